@@ -0,0 +1,122 @@
+package bitboard
+
+// Attack generators for chess pieces on an 8x8 board. Each returns a bitmap
+// of the squares attacked by a piece on bit position p.
+
+var (
+	knightAttacks [64]uint64
+	kingAttacks   [64]uint64
+	pawnAttacks   [2][64]uint64
+)
+
+var (
+	knightOffsets = [][2]int{
+		{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2},
+	}
+	kingOffsets = [][2]int{
+		{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1},
+	}
+	rookDirections   = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	bishopDirections = [][2]int{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+)
+
+func init() {
+	for p := 0; p < 64; p++ {
+		knightAttacks[p] = offsetMask(p, knightOffsets)
+		kingAttacks[p] = offsetMask(p, kingOffsets)
+		pawnAttacks[White][p] = offsetMask(p, [][2]int{{-1, 1}, {1, 1}})
+		pawnAttacks[Black][p] = offsetMask(p, [][2]int{{-1, -1}, {1, -1}})
+	}
+}
+
+// offsetMask returns the bitmap of on-board squares reached from p by each
+// (x, y) offset.
+func offsetMask(p int, offsets [][2]int) uint64 {
+	var mask uint64
+	x, y := BitToCartesian(p, 8)
+	for _, o := range offsets {
+		i, j := x+o[0], y+o[1]
+		if i >= 0 && i < 8 && j >= 0 && j < 8 {
+			SetBit(&mask, CartesianToBit(i, j, 8))
+		}
+	}
+	return mask
+}
+
+// slidingAttacks returns the bitmap of squares reached from p along each
+// direction, stopping at (and including) the first occupied square.
+func slidingAttacks(p int, occupied uint64, directions [][2]int) uint64 {
+	var mask uint64
+	x, y := BitToCartesian(p, 8)
+	for _, d := range directions {
+		for i, j := x+d[0], y+d[1]; i >= 0 && i < 8 && j >= 0 && j < 8; i, j = i+d[0], j+d[1] {
+			q := CartesianToBit(i, j, 8)
+			SetBit(&mask, q)
+			if IsBitSet(occupied, q) {
+				break
+			}
+		}
+	}
+	return mask
+}
+
+// KnightAttacks returns the squares attacked by a knight on p.
+func KnightAttacks(p int) uint64 {
+	return knightAttacks[p]
+}
+
+// KnightDistance returns the minimum number of knight moves between squares
+// from and to on an 8x8 board. Every square of the full board is reachable,
+// so it never returns -1; use KnightDistanceMask to restrict the squares.
+func KnightDistance(from int, to int) int {
+	return KnightDistanceMask(from, to, ^uint64(0))
+}
+
+// KnightDistanceMask returns the minimum number of knight moves from square
+// from to square to on an 8x8 board, landing only on the squares in mask, or
+// -1 if to cannot be reached.
+func KnightDistanceMask(from int, to int, mask uint64) int {
+	var reached uint64
+	SetBit(&reached, from)
+	frontier := reached
+	for d := 0; frontier != 0; d++ {
+		if IsBitSet(frontier, to) {
+			return d
+		}
+		var next uint64
+		for ; frontier != 0; frontier &= frontier - 1 {
+			next |= KnightAttacks(LSB(frontier))
+		}
+		frontier = next & mask &^ reached
+		reached |= frontier
+	}
+	return -1
+}
+
+// KingAttacks returns the squares attacked by a king on p.
+func KingAttacks(p int) uint64 {
+	return kingAttacks[p]
+}
+
+// PawnAttacks returns the squares attacked by a pawn of the given player on p.
+func PawnAttacks(p int, player int) uint64 {
+	return pawnAttacks[player][p]
+}
+
+// RookAttacks returns the squares attacked by a rook on p, given the occupied
+// squares that block its movement.
+func RookAttacks(p int, occupied uint64) uint64 {
+	return slidingAttacks(p, occupied, rookDirections)
+}
+
+// BishopAttacks returns the squares attacked by a bishop on p, given the
+// occupied squares that block its movement.
+func BishopAttacks(p int, occupied uint64) uint64 {
+	return slidingAttacks(p, occupied, bishopDirections)
+}
+
+// QueenAttacks returns the squares attacked by a queen on p, given the
+// occupied squares that block its movement.
+func QueenAttacks(p int, occupied uint64) uint64 {
+	return RookAttacks(p, occupied) | BishopAttacks(p, occupied)
+}
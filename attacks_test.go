@@ -0,0 +1,118 @@
+package bitboard
+
+import "testing"
+
+func TestKnightAttacks(t *testing.T) {
+	expected := map[int]uint64{
+		0:  0x0000000000020400, // a1: b3, c2
+		28: 0x0000284400442800, // e4
+		63: 0x0020400000000000, // h8: f7, g6
+	}
+	for p, mask := range expected {
+		result := KnightAttacks(p)
+		if result != mask {
+			t.Errorf("Expected %#016x, got %#016x", mask, result)
+		}
+	}
+}
+
+func TestKnightDistance(t *testing.T) {
+	expected := []struct {
+		from, to int
+		distance int
+	}{
+		{0, 0, 0},
+		{0, 17, 1}, // a1-b3
+		{0, 9, 4},  // a1-b2
+		{0, 63, 6}, // a1-h8
+		{28, 36, 3},
+	}
+	for _, e := range expected {
+		if result := KnightDistance(e.from, e.to); result != e.distance {
+			t.Error("Expected", e.distance, "from", e.from, "to", e.to, ", got", result)
+		}
+	}
+	// A knight in the corner cannot leave if b3 and c2 are excluded.
+	if result := KnightDistanceMask(0, 63, ^uint64(0x0000000000020400)); result != -1 {
+		t.Error("Expected -1, got", result)
+	}
+	// Nor can it land on b3 if b3 is excluded.
+	if result := KnightDistanceMask(0, 17, ^uint64(1<<17)); result != -1 {
+		t.Error("Expected -1, got", result)
+	}
+	// Excluding c2 leaves the route through b3.
+	if result := KnightDistanceMask(0, 63, ^uint64(1<<10)); result != 6 {
+		t.Error("Expected 6, got", result)
+	}
+}
+
+func TestKingAttacks(t *testing.T) {
+	expected := map[int]uint64{
+		0:  0x0000000000000302, // a1
+		28: 0x0000003828380000, // e4
+	}
+	for p, mask := range expected {
+		result := KingAttacks(p)
+		if result != mask {
+			t.Errorf("Expected %#016x, got %#016x", mask, result)
+		}
+	}
+}
+
+func TestPawnAttacks(t *testing.T) {
+	if result := PawnAttacks(12, White); result != 0x0000000000280000 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0000000000280000, result)
+	}
+	if result := PawnAttacks(52, Black); result != 0x0000280000000000 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0000280000000000, result)
+	}
+	if result := PawnAttacks(8, White); result != 0x0000000000020000 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0000000000020000, result)
+	}
+}
+
+func TestSlidingAttacks(t *testing.T) {
+	// Rook on a1 with a blocker on a4 and the rest of the first rank empty.
+	var occupied uint64
+	SetBit(&occupied, 24)
+	if result := RookAttacks(0, occupied); result != 0x00000000010101fe {
+		t.Errorf("Expected %#016x, got %#016x", 0x00000000010101fe, result)
+	}
+	// Bishop on c1 on an empty board.
+	if result := BishopAttacks(2, 0); result != 0x0000804020110a00 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0000804020110a00, result)
+	}
+	// Queen on d1 hemmed in by the starting position.
+	b := NewChessBoard()
+	if result := QueenAttacks(3, b.Occupied); result != 0x0000000000001c14 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0000000000001c14, result)
+	}
+}
+
+func TestAttackersTo(t *testing.T) {
+	// Target e4 is attacked by a white knight (f2), black pawn (d5), white
+	// rook (e1), black queen (h4), and white king (f3). The black bishop on b7
+	// is blocked by the pawn, and the white bishop on c1 is not aligned.
+	b, err := ParseFEN("4k3/1b6/8/3p4/7q/5K2/5N2/2B1R3 w")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	var expected uint64
+	for _, p := range []string{"f2", "d5", "e1", "h4", "f3"} {
+		SetBit(&expected, b.AlgebraicToBit(p))
+	}
+	e4 := b.AlgebraicToBit("e4")
+	if result := b.AttackersTo(e4, b.Occupied); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	// Removing the d5 pawn from the occupancy reveals the bishop behind it.
+	occupied := b.Occupied
+	ClearBit(&occupied, b.AlgebraicToBit("d5"))
+	SetBit(&expected, b.AlgebraicToBit("b7"))
+	if result := b.AttackersTo(e4, occupied); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	if result := b.AttackersTo(b.AlgebraicToBit("a5"), b.Occupied); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+}
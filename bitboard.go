@@ -5,8 +5,16 @@ package bitboard
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
 )
 
+// MaxSquares is the largest number of squares a Bitboard can hold: one for
+// each bit of a bitmap.
+const MaxSquares = 64
+
 // A Bitboard represents game state.
 //
 // We use a little-endian mapping of bits to rank-and-file coordinates. For
@@ -43,28 +51,166 @@ import (
 // Construct a new Bitboard using New. There are also convenience
 // functions for constructing bitboards for specific games.
 type Bitboard struct {
-	Bitmaps  []uint64 // Bitmaps for each colour/piece combination
-	Symbols  []string // Symbols representing each colour/piece combination
-	Occupied uint64   // Union of all bitmaps (occupied squares)
-	Ranks    int      // Number of rows
-	Files    int      // Number of columns
+	Bitmaps        []uint64 // Bitmaps for each colour/piece combination
+	Symbols        []string // Symbols representing each colour/piece combination
+	Occupied       uint64   // Union of all bitmaps (occupied squares)
+	Ranks          int      // Number of rows
+	Files          int      // Number of columns
+	Playable       uint64   // Squares pieces may occupy, or 0 for all squares
+	SideToMove     int      // Player whose turn it is
+	EnPassant      int      // En passant target square (chess), or -1 if none
+	CastlingRights uint8    // Castling availability (chess)
+	Kings          uint64   // Crowned pieces (checkers)
+	HalfmoveClock  int      // Moves since the last capture or pawn move (chess)
+	FullmoveNumber int      // Number of the current move, starting at 1 (chess)
+
+	// TopLeftOrigin makes Cartesian coordinates count ranks down from the top
+	// of the board, as many UIs do, instead of up from the bottom. It only
+	// affects coordinates: bit positions, algebraic notation, and printing
+	// are unaffected. The printed board always shows the last rank at the
+	// top, so with TopLeftOrigin set, (0, 0) is its top-left square.
+	TopLeftOrigin bool
+
+	// counts caches the number of pieces in each bitmap for Counts. It is
+	// reset to nil, marking it dirty, whenever pieces are placed, removed, or
+	// moved.
+	counts []int
+}
+
+// PrintOptions control how Fprint renders a Bitboard.
+type PrintOptions struct {
+	// Highlight marks squares to draw attention to, such as an attack set.
+	// Highlighted empty squares are drawn as asterisks and highlighted pieces
+	// are drawn with their symbols in brackets.
+	Highlight uint64
+
+	// EmptySymbol represents empty squares. It defaults to a period.
+	EmptySymbol string
+
+	// UnplayableSymbol represents squares that are not playable. It defaults
+	// to a space.
+	UnplayableSymbol string
+
+	// CellSeparator is written between the squares of each rank. It defaults
+	// to nothing.
+	CellSeparator string
 }
 
 // PrettyPrint pretty-prints a Bitboard using the symbols for each colour/piece
 // combination. Empty squares are represented by periods.
 func (b *Bitboard) PrettyPrint() {
+	b.Fprint(os.Stdout, PrintOptions{})
+}
+
+// Fprint pretty-prints a Bitboard to w like PrettyPrint, using the given
+// options. Squares are padded to a common display width so that columns line
+// up when symbols have different lengths, counting wide characters such as
+// CJK ideographs and emoji as two columns and combining marks as none. The
+// last rank is printed first whether or not TopLeftOrigin is set.
+func (b *Bitboard) Fprint(w io.Writer, opts PrintOptions) error {
+	empty := opts.EmptySymbol
+	if empty == "" {
+		empty = "."
+	}
+	unplayable := opts.UnplayableSymbol
+	if unplayable == "" {
+		unplayable = " "
+	}
+	cells := make([]string, b.Ranks*b.Files)
+	width := 0
+	for p := range cells {
+		i := b.GetBitmapIndex(p)
+		highlighted := IsBitSet(opts.Highlight, p)
+		switch {
+		case !IsBitSet(b.playable(), p):
+			cells[p] = unplayable
+		case i != -1 && highlighted:
+			cells[p] = "[" + b.Symbols[i] + "]"
+		case i != -1:
+			cells[p] = b.Symbols[i]
+		case highlighted:
+			cells[p] = "*"
+		default:
+			cells[p] = empty
+		}
+		if n := displayWidth(cells[p]); n > width {
+			width = n
+		}
+	}
+	var s strings.Builder
 	for r := b.Ranks; r > 0; r-- {
 		for f := 0; f < b.Files; f++ {
-			p := (r-1)*b.Files + f
-			i := b.GetBitmapIndex(p)
-			if i != -1 {
-				fmt.Print(b.Symbols[i])
-			} else {
-				fmt.Print(".")
+			cell := cells[(r-1)*b.Files+f]
+			s.WriteString(cell)
+			if f < b.Files-1 {
+				s.WriteString(strings.Repeat(" ", width-displayWidth(cell)))
+				s.WriteString(opts.CellSeparator)
 			}
 		}
-		fmt.Println()
+		s.WriteString("\n")
+	}
+	_, err := io.WriteString(w, s.String())
+	return err
+}
+
+// wideRanges lists the blocks of characters that terminals display two
+// columns wide: Hangul Jamo, CJK, Hangul syllables, fullwidth forms, and
+// emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe30, 0xfe4f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff},
+	{0x20000, 0x3fffd},
+}
+
+// displayWidth returns the number of terminal columns taken up by s.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+			continue
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// isWide reports whether r is displayed two columns wide.
+func isWide(r rune) bool {
+	for _, w := range wideRanges {
+		if r >= w[0] && r <= w[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// FprintLayer pretty-prints only bitmap m to w, showing its symbol on the
+// squares it occupies and a period on every other square, including squares
+// that are not playable.
+func (b *Bitboard) FprintLayer(w io.Writer, m int) error {
+	c := b.Clone()
+	for i := range c.Bitmaps {
+		if i != m {
+			c.Bitmaps[i] = 0
+		}
 	}
+	c.RecomputeOccupied()
+	return c.Fprint(w, PrintOptions{UnplayableSymbol: "."})
 }
 
 // GetBitmapIndex returns the array index of the bitmap including a particular
@@ -83,16 +229,478 @@ func (b *Bitboard) GetBitmapIndex(p int) int {
 	return -1 // not found
 }
 
+// SymbolAt returns the symbol of the piece occupying bit position p, or an
+// empty string if the square is vacant.
+func (b *Bitboard) SymbolAt(p int) string {
+	i := b.GetBitmapIndex(p)
+	if i == -1 {
+		return ""
+	}
+	return b.Symbols[i]
+}
+
+// SymbolAtAlgebraic returns the symbol of the piece occupying algebraic
+// coordinate p, or an empty string if the square is vacant.
+func (b *Bitboard) SymbolAtAlgebraic(p string) string {
+	return b.SymbolAt(b.AlgebraicToBit(p))
+}
+
+// SymbolAtCartesian returns the symbol of the piece occupying Cartesian
+// coordinates (x, y), or an empty string if the square is vacant.
+func (b *Bitboard) SymbolAtCartesian(x int, y int) string {
+	return b.SymbolAt(b.CartesianToBit(x, y))
+}
+
+// SetSymbols replaces the symbols representing each bitmap, e.g., to use
+// Unicode glyphs instead of letters. There must be one symbol per bitmap.
+func (b *Bitboard) SetSymbols(symbols []string) error {
+	if len(symbols) != len(b.Bitmaps) {
+		return fmt.Errorf("bitboard: expected %d symbols, got %d", len(b.Bitmaps), len(symbols))
+	}
+	b.Symbols = append([]string(nil), symbols...)
+	return nil
+}
+
+// RecomputeOccupied rebuilds the occupancy bitmap from the union of all
+// bitmaps. Use it to repair a board whose bitmaps were modified directly.
+func (b *Bitboard) RecomputeOccupied() {
+	b.Occupied = UnionSlice(b.Bitmaps)
+	b.counts = nil
+}
+
+// CheckInvariants verifies that each bitmap has a symbol, that the occupancy
+// bitmap matches the union of all bitmaps, and that no square is claimed by
+// more than one bitmap.
+func (b *Bitboard) CheckInvariants() error {
+	if len(b.Symbols) != len(b.Bitmaps) {
+		return fmt.Errorf("bitboard: %d symbols for %d bitmaps", len(b.Symbols), len(b.Bitmaps))
+	}
+	if b.Occupied != UnionSlice(b.Bitmaps) {
+		return errors.New("bitboard: occupancy bitmap does not match union of bitmaps")
+	}
+	var seen uint64
+	for i, m := range b.Bitmaps {
+		if seen&m != 0 {
+			return fmt.Errorf("bitboard: bitmap %d overlaps another bitmap", i)
+		}
+		seen |= m
+	}
+	if b.Kings&^b.Occupied != 0 {
+		return errors.New("bitboard: kings bitmap marks empty squares")
+	}
+	return nil
+}
+
+// ToMove returns the player whose turn it is.
+func (b *Bitboard) ToMove() int {
+	return b.SideToMove
+}
+
+// SetToMove sets the player whose turn it is.
+func (b *Bitboard) SetToMove(player int) {
+	b.SideToMove = player
+}
+
+// HasLine reports whether bitmap m contains a straight line of n pieces
+// horizontally, vertically, or diagonally.
+func (b *Bitboard) HasLine(m int, n int) bool {
+	for _, line := range LineMasks(b.Ranks, b.Files, n) {
+		if b.Bitmaps[m]&line == line {
+			return true
+		}
+	}
+	return false
+}
+
+// Winner returns the index of the first bitmap that contains a straight line
+// of n pieces, or -1 if none does. Every bitmap is checked, so it works for
+// games with any number of players.
+func (b *Bitboard) Winner(n int) int {
+	lines := LineMasks(b.Ranks, b.Files, n)
+	for m, bitmap := range b.Bitmaps {
+		for _, line := range lines {
+			if bitmap&line == line {
+				return m
+			}
+		}
+	}
+	return -1
+}
+
+// Diff describes the pieces that appear and disappear going from b to other.
+// The maps are keyed by bit position and hold the index of the bitmap that
+// gained or lost a piece on that square.
+func (b *Bitboard) Diff(other *Bitboard) (added map[int]int, removed map[int]int, err error) {
+	if b.Ranks != other.Ranks || b.Files != other.Files || len(b.Bitmaps) != len(other.Bitmaps) {
+		return nil, nil, errors.New("bitboard: cannot diff boards with different dimensions")
+	}
+	added = map[int]int{}
+	removed = map[int]int{}
+	for i := range b.Bitmaps {
+		for m := other.Bitmaps[i] &^ b.Bitmaps[i]; m != 0; m &= m - 1 {
+			added[LSB(m)] = i
+		}
+		for m := b.Bitmaps[i] &^ other.Bitmaps[i]; m != 0; m &= m - 1 {
+			removed[LSB(m)] = i
+		}
+	}
+	return added, removed, nil
+}
+
+// Grid returns the board as a matrix of ranks and files. Each cell holds the
+// index of the bitmap occupying that square, or -1 if it is empty. Row 0 is
+// the first rank, or the top rank if TopLeftOrigin is set.
+func (b *Bitboard) Grid() [][]int {
+	grid := make([][]int, b.Ranks)
+	for y := range grid {
+		grid[y] = make([]int, b.Files)
+		for x := range grid[y] {
+			grid[y][x] = b.GetBitmapIndex(b.CartesianToBit(x, y))
+		}
+	}
+	return grid
+}
+
+// BitmapFor returns the bitmap represented by symbol, and whether the board
+// has such a symbol. If several bitmaps share the symbol, their union is
+// returned.
+func (b *Bitboard) BitmapFor(symbol string) (uint64, bool) {
+	var bitmap uint64
+	found := false
+	for m, s := range b.Symbols {
+		if s == symbol {
+			bitmap |= b.Bitmaps[m]
+			found = true
+		}
+	}
+	return bitmap, found
+}
+
+// SquareOf returns the position of the only piece in bitmap m, and false if
+// the bitmap holds no pieces or more than one.
+func (b *Bitboard) SquareOf(m int) (int, bool) {
+	if PopCount(b.Bitmaps[m]) != 1 {
+		return -1, false
+	}
+	return LSB(b.Bitmaps[m]), true
+}
+
+// SetBits returns the positions of the pieces in bitmap m in ascending order.
+func (b *Bitboard) SetBits(m int) []int {
+	return Bits(b.Bitmaps[m])
+}
+
+// Counts returns the number of pieces in each bitmap. The counts are cached
+// until pieces are next placed, removed, or moved, so a board whose bitmaps
+// were modified directly must be repaired with RecomputeOccupied first.
+func (b *Bitboard) Counts() []int {
+	if b.counts == nil || len(b.counts) != len(b.Bitmaps) {
+		// Clones share the cache, so build a new one rather than update it.
+		counts := make([]int, len(b.Bitmaps))
+		for i, m := range b.Bitmaps {
+			counts[i] = PopCount(m)
+		}
+		b.counts = counts
+	}
+	return append([]int(nil), b.counts...)
+}
+
+// Pieces returns a map from each symbol on the board to the algebraic
+// coordinates of the squares it occupies, in ascending bit order. Symbols with
+// no pieces on the board are omitted.
+func (b *Bitboard) Pieces() map[string][]string {
+	pieces := make(map[string][]string)
+	for m, bitmap := range b.Bitmaps {
+		for ; bitmap != 0; bitmap &= bitmap - 1 {
+			pieces[b.Symbols[m]] = append(pieces[b.Symbols[m]], b.BitToAlgebraic(LSB(bitmap)))
+		}
+	}
+	return pieces
+}
+
+// Scroll shifts every piece on the board by (dx, dy). Pieces pushed off an
+// edge are removed, or reappear at the opposite edge if wrap is true. The
+// playable squares stay where they are.
+func (b *Bitboard) Scroll(dx int, dy int, wrap bool) {
+	for i, m := range b.Bitmaps {
+		b.Bitmaps[i] = shift(m, dx, dy, b.Files, b.Ranks, wrap)
+	}
+	b.Kings = shift(b.Kings, dx, dy, b.Files, b.Ranks, wrap)
+	b.RecomputeOccupied()
+}
+
+// Centroid returns the mean Cartesian coordinates of the pieces in bitmap m,
+// and false if the bitmap is empty.
+func (b *Bitboard) Centroid(m int) (x float64, y float64, ok bool) {
+	n := PopCount(b.Bitmaps[m])
+	if n == 0 {
+		return 0, 0, false
+	}
+	for bitmap := b.Bitmaps[m]; bitmap != 0; bitmap &= bitmap - 1 {
+		i, j := b.BitToCartesian(LSB(bitmap))
+		x += float64(i)
+		y += float64(j)
+	}
+	return x / float64(n), y / float64(n), true
+}
+
+// Liberties returns the number of empty squares orthogonally adjacent to the
+// group of connected stones in bitmap player that contains pos, as in Go. It
+// returns 0 if pos does not hold one of the player's stones.
+func (b *Bitboard) Liberties(player int, pos int) int {
+	var seed uint64
+	SetBit(&seed, pos)
+	group := FloodFill(seed, b.Bitmaps[player], b.Files)
+	if group == 0 {
+		return 0
+	}
+	return PopCount(orthogonalShifts(group, b.Files) & b.Empty())
+}
+
+// boardMask returns a bitmap of every square on the board.
+func (b *Bitboard) boardMask() uint64 {
+	return (1 << uint(b.Ranks*b.Files)) - 1
+}
+
+// playable returns a bitmap of the squares on the board that pieces may
+// occupy.
+func (b *Bitboard) playable() uint64 {
+	if b.Playable == 0 {
+		return b.boardMask()
+	}
+	return b.Playable & b.boardMask()
+}
+
+// Empty returns a bitmap of the playable squares that are not occupied.
+func (b *Bitboard) Empty() uint64 {
+	return b.playable() &^ b.Occupied
+}
+
+// Clone returns a deep copy of the board.
+func (b *Bitboard) Clone() *Bitboard {
+	c := *b
+	c.Bitmaps = append([]uint64(nil), b.Bitmaps...)
+	c.Symbols = append([]string(nil), b.Symbols...)
+	return &c
+}
+
+// A Snapshot records the pieces on a board and the state of play, so the
+// board can be rolled back with Restore.
+type Snapshot struct {
+	bitmaps        []uint64
+	occupied       uint64
+	sideToMove     int
+	enPassant      int
+	castling       uint8
+	kings          uint64
+	halfmoveClock  int
+	fullmoveNumber int
+}
+
+// Snapshot records the current position for a later call to Restore.
+func (b *Bitboard) Snapshot() Snapshot {
+	return Snapshot{
+		bitmaps:        append([]uint64(nil), b.Bitmaps...),
+		occupied:       b.Occupied,
+		sideToMove:     b.SideToMove,
+		enPassant:      b.EnPassant,
+		castling:       b.CastlingRights,
+		kings:          b.Kings,
+		halfmoveClock:  b.HalfmoveClock,
+		fullmoveNumber: b.FullmoveNumber,
+	}
+}
+
+// Restore reverts the board to the position recorded by s.
+func (b *Bitboard) Restore(s Snapshot) {
+	b.Bitmaps = append(b.Bitmaps[:0], s.bitmaps...)
+	b.Occupied = s.occupied
+	b.counts = nil
+	b.SideToMove = s.sideToMove
+	b.EnPassant = s.enPassant
+	b.CastlingRights = s.castling
+	b.Kings = s.kings
+	b.HalfmoveClock = s.halfmoveClock
+	b.FullmoveNumber = s.fullmoveNumber
+}
+
+// Flipped returns a copy of the board rotated by 180 degrees, so that the
+// player at the top of the board is shown at the bottom.
+func (b *Bitboard) Flipped() *Bitboard {
+	return b.transform(b.Files, b.Ranks, func(x, y int) (int, int) {
+		return b.Files - 1 - x, b.Ranks - 1 - y
+	})
+}
+
+// Transform returns a copy of the board flipped or rotated as named:
+//
+//   - "identity" leaves it as it is
+//   - "flipv" flips it vertically, swapping the first and last ranks
+//   - "fliph" flips it horizontally, swapping the first and last files
+//   - "rot90", "rot180", and "rot270" rotate it clockwise by that many degrees
+//   - "diaga1h8" and "diaga8h1" flip it about the diagonal through the named
+//     corners
+//
+// Rotating by 90 or 270 degrees, or flipping about a diagonal, swaps the
+// number of ranks and files. As with Flipped, castling and en passant rights
+// are cleared.
+func (b *Bitboard) Transform(name string) (*Bitboard, error) {
+	w, h := b.Files, b.Ranks
+	switch name {
+	case "identity":
+		return b.transform(w, h, func(x, y int) (int, int) { return x, y }), nil
+	case "flipv":
+		return b.transform(w, h, func(x, y int) (int, int) { return x, h - 1 - y }), nil
+	case "fliph":
+		return b.transform(w, h, func(x, y int) (int, int) { return w - 1 - x, y }), nil
+	case "rot90":
+		return b.transform(h, w, func(x, y int) (int, int) { return y, w - 1 - x }), nil
+	case "rot180":
+		return b.Flipped(), nil
+	case "rot270":
+		return b.transform(h, w, func(x, y int) (int, int) { return h - 1 - y, x }), nil
+	case "diaga1h8":
+		return b.transform(h, w, func(x, y int) (int, int) { return y, x }), nil
+	case "diaga8h1":
+		return b.transform(h, w, func(x, y int) (int, int) { return h - 1 - y, w - 1 - x }), nil
+	}
+	return nil, fmt.Errorf("bitboard: unknown transform %q", name)
+}
+
+// symmetries lists the names Transform accepts for the eight symmetries of a
+// square board. The first four keep the shape of any rectangular board.
+var symmetries = []string{"identity", "flipv", "fliph", "rot180", "rot90", "rot270", "diaga1h8", "diaga8h1"}
+
+// symmetries returns the names of the transforms that map the board onto a
+// board of the same shape.
+func (b *Bitboard) symmetries() []string {
+	if b.Ranks != b.Files {
+		return symmetries[:4]
+	}
+	return symmetries
+}
+
+// Canonical returns the copy of the board, among its rotations and
+// reflections, whose bitmaps are lexicographically smallest. Positions that
+// are symmetric to one another share a canonical form, which is useful as a
+// transposition table key. Rectangular boards only have four symmetries. As
+// with Transform, castling and en passant rights are cleared.
+func (b *Bitboard) Canonical() *Bitboard {
+	var canonical *Bitboard
+	for _, name := range b.symmetries() {
+		c, _ := b.Transform(name)
+		if canonical == nil || lessBitmaps(c.Bitmaps, canonical.Bitmaps) {
+			canonical = c
+		}
+	}
+	return canonical
+}
+
+// SymmetryGroup returns the names of the transforms, as accepted by Transform,
+// that leave the position unchanged. It always includes "identity"; an empty
+// square board returns all eight. Only the placement of pieces is compared.
+func (b *Bitboard) SymmetryGroup() []string {
+	var names []string
+	for _, name := range b.symmetries() {
+		c, _ := b.Transform(name)
+		if !lessBitmaps(c.Bitmaps, b.Bitmaps) && !lessBitmaps(b.Bitmaps, c.Bitmaps) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lessBitmaps reports whether bitmaps a sort before bitmaps b, comparing them
+// in order.
+func lessBitmaps(a []uint64, b []uint64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// TrimToFit returns a copy of the board cropped to the smallest rectangle of
+// ranks and files that contains every piece. An empty board is returned as
+// an unchanged copy.
+func (b *Bitboard) TrimToFit() *Bitboard {
+	if b.Occupied == 0 {
+		return b.Clone()
+	}
+	minX, minY, maxX, maxY := b.Files, b.Ranks, -1, -1
+	for occupied := b.Occupied; occupied != 0; occupied &= occupied - 1 {
+		x, y := BitToCartesian(LSB(occupied), b.Files)
+		if x < minX {
+			minX = x
+		}
+		if x > maxX {
+			maxX = x
+		}
+		if y < minY {
+			minY = y
+		}
+		if y > maxY {
+			maxY = y
+		}
+	}
+	// Only the playable squares inside the rectangle survive.
+	c := b.Clone()
+	if c.Playable != 0 {
+		var box uint64
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				SetBit(&box, CartesianToBit(x, y, b.Files))
+			}
+		}
+		c.Playable &= box
+	}
+	return c.transform(maxX-minX+1, maxY-minY+1, func(x, y int) (int, int) {
+		return x - minX, y - minY
+	})
+}
+
+// transform returns a copy of the board with every square (x, y) moved to
+// f(x, y) on a board with the given number of files and ranks. Castling and en
+// passant rights do not survive the transformation and are cleared.
+func (b *Bitboard) transform(files int, ranks int, f func(x, y int) (int, int)) *Bitboard {
+	c := b.Clone()
+	c.Files, c.Ranks = files, ranks
+	c.CastlingRights, c.EnPassant = 0, -1
+	move := func(i uint64) uint64 {
+		var moved uint64
+		for ; i != 0; i &= i - 1 {
+			x, y := f(BitToCartesian(LSB(i), b.Files))
+			SetBit(&moved, CartesianToBit(x, y, files))
+		}
+		return moved
+	}
+	for i, m := range b.Bitmaps {
+		c.Bitmaps[i] = move(m)
+	}
+	c.Playable = move(b.Playable)
+	c.Kings = move(b.Kings)
+	c.RecomputeOccupied()
+	return c
+}
+
 // Convert coordinates in algebraic notation to an integer bit position.
 // Wrap AlgebraicToBit to automatically pass in number of files.
 func (b *Bitboard) AlgebraicToBit(p string) int {
 	return AlgebraicToBit(p, b.Files)
 }
 
-// Convert coordinates in algebraic notiton to Cartesian coordinates.
-// Wrap AlgebraicToCartesian to automatically pass in number of files.
+// Convert coordinates in algebraic notiton to Cartesian coordinates,
+// honouring TopLeftOrigin.
 func (b *Bitboard) AlgebraicToCartesian(p string) (int, int) {
-	return AlgebraicToCartesian(p, b.Files)
+	return b.BitToCartesian(b.AlgebraicToBit(p))
+}
+
+// Convert coordinates in algebraic notation to an integer bit position,
+// validating them against the board's dimensions.
+// Wrap ParseAlgebraic to automatically pass in number of files and ranks.
+func (b *Bitboard) ParseAlgebraic(p string) (int, error) {
+	return ParseAlgebraic(p, b.Files, b.Ranks)
 }
 
 // Convert an integer bit position to coordiantes in algebraic notation.
@@ -101,59 +709,151 @@ func (b *Bitboard) BitToAlgebraic(p int) string {
 	return BitToAlgebraic(p, b.Files)
 }
 
+// Convert an integer bit position to coordinates in algebraic notation as
+// labelled on the board seen from the opposite side, i.e. on the board
+// returned by Flipped.
+func (b *Bitboard) BitToAlgebraicFlipped(p int) string {
+	return BitToAlgebraic(b.Ranks*b.Files-1-p, b.Files)
+}
+
+// Convert coordinates in algebraic notation, as labelled on the board seen
+// from the opposite side, to an integer bit position. This is the inverse of
+// BitToAlgebraicFlipped.
+func (b *Bitboard) AlgebraicToBitFlipped(p string) int {
+	return b.Ranks*b.Files - 1 - AlgebraicToBit(p, b.Files)
+}
+
 // Convert an integer bit position to Cartesian coordinates.
-// Wrap BitToCartesian to automatically pass in number of files.
+// Wrap BitToCartesian to automatically pass in number of files, counting
+// ranks from the top if TopLeftOrigin is set.
 func (b *Bitboard) BitToCartesian(p int) (int, int) {
-	return BitToCartesian(p, b.Files)
+	x, y := BitToCartesian(p, b.Files)
+	if b.TopLeftOrigin {
+		y = b.Ranks - 1 - y
+	}
+	return x, y
 }
 
-// Convert Cartesian coordinates to coordinates in algebraic notation.
-// Wrap CartesianToAlgebraic to automatically pass in number of files.
+// Convert Cartesian coordinates to coordinates in algebraic notation,
+// honouring TopLeftOrigin.
 func (b *Bitboard) CartesianToAlgebraic(x int, y int) string {
-	return CartesianToAlgebraic(x, y, b.Files)
+	return b.BitToAlgebraic(b.CartesianToBit(x, y))
 }
 
 // Convert Cartesian coordinates to an integer bit position.
-// Wrap CartesianToBit to automatically pass in number of files.
+// Wrap CartesianToBit to automatically pass in number of files, counting
+// ranks from the top if TopLeftOrigin is set.
 func (b *Bitboard) CartesianToBit(x int, y int) int {
+	if b.TopLeftOrigin {
+		y = b.Ranks - 1 - y
+	}
 	return CartesianToBit(x, y, b.Files)
 }
 
 // Move a piece from algebraic position p1 to p2.
-func (b *Bitboard) MovePieceAlgebraic(m int, p1 string, p2 string) {
-	b.RemovePieceAlgebraic(m, p1)
-	b.PlacePieceAlgebraic(m, p2)
+func (b *Bitboard) MovePieceAlgebraic(m int, p1 string, p2 string) error {
+	from, err := b.ParseAlgebraic(p1)
+	if err != nil {
+		return err
+	}
+	to, err := b.ParseAlgebraic(p2)
+	if err != nil {
+		return err
+	}
+	return b.MovePieceBit(m, from, to)
 }
 
-// Move a piece from bit position p1 to p2.
-func (b *Bitboard) MovePieceBit(m int, p1 int, p2 int) {
+// Move a piece from bit position p1 to p2. Moving a piece to its own square
+// does nothing. The piece is not moved if p2 is not playable or already holds
+// a piece of the same bitmap.
+func (b *Bitboard) MovePieceBit(m int, p1 int, p2 int) error {
+	if p1 == p2 {
+		return nil
+	}
+	if !IsBitSet(b.playable(), p2) {
+		return fmt.Errorf("bitboard: square %d is not playable", p2)
+	}
+	if IsBitSet(b.Bitmaps[m], p2) {
+		return fmt.Errorf("bitboard: square %d is already occupied by bitmap %d", p2, m)
+	}
+	b.movePieceBit(m, p1, p2)
+	return nil
+}
+
+// movePieceBit moves a piece from bit position p1 to p2 without checking that
+// p2 is playable.
+func (b *Bitboard) movePieceBit(m int, p1 int, p2 int) {
 	b.RemovePieceBit(m, p1)
-	b.PlacePieceBit(m, p2)
+	b.placePieceBit(m, p2)
 }
 
 // Move a piece using Cartesian coordinates.
-func (b *Bitboard) MovePieceCartesian(m int, x1 int, y1 int, x2 int, y2 int) {
-	b.RemovePieceCartesian(m, x1, y1)
-	b.PlacePieceCartesian(m, x2, y2)
+func (b *Bitboard) MovePieceCartesian(m int, x1 int, y1 int, x2 int, y2 int) error {
+	return b.MovePieceBit(m, b.CartesianToBit(x1, y1), b.CartesianToBit(x2, y2))
 }
 
 // Place the piece at algebraic coordinate p.
-func (b *Bitboard) PlacePieceAlgebraic(m int, p string) {
-	i := b.AlgebraicToBit(p)
-	b.PlacePieceBit(m, i)
+func (b *Bitboard) PlacePieceAlgebraic(m int, p string) error {
+	i, err := b.ParseAlgebraic(p)
+	if err != nil {
+		return err
+	}
+	return b.PlacePieceBit(m, i)
+}
+
+// Place the piece at bit position p. Pieces cannot be placed on squares that
+// are not playable.
+func (b *Bitboard) PlacePieceBit(m int, p int) error {
+	if !IsBitSet(b.playable(), p) {
+		return fmt.Errorf("bitboard: square %d is not playable", p)
+	}
+	b.placePieceBit(m, p)
+	return nil
 }
 
-// Place the piece at bit position p.
-func (b *Bitboard) PlacePieceBit(m int, p int) {
+// placePieceBit places the piece at bit position p without checking that p is
+// playable.
+func (b *Bitboard) placePieceBit(m int, p int) {
 	// Update the occupancy bitmap.
 	SetBit(&b.Occupied, p)
 	SetBit(&b.Bitmaps[m], p)
+	b.counts = nil
 }
 
 // Place the piece at Cartesian coordinates (x, y).
-func (b *Bitboard) PlacePieceCartesian(m int, x int, y int) {
+func (b *Bitboard) PlacePieceCartesian(m int, x int, y int) error {
 	p := b.CartesianToBit(x, y)
-	b.PlacePieceBit(m, p)
+	return b.PlacePieceBit(m, p)
+}
+
+// Place pieces of the same bitmap at each of the algebraic coordinates in
+// positions. All coordinates are validated first, so an error identifying the
+// first bad coordinate leaves the board unchanged.
+func (b *Bitboard) PlacePieces(m int, positions ...string) error {
+	bits := make([]int, len(positions))
+	for i, p := range positions {
+		pos, err := b.ParseAlgebraic(p)
+		if err != nil {
+			return err
+		}
+		bits[i] = pos
+	}
+	return b.PlacePiecesBit(m, bits...)
+}
+
+// Place pieces of the same bitmap at each of the bit positions in positions.
+// As with PlacePieces, the board is unchanged if any position is not
+// playable.
+func (b *Bitboard) PlacePiecesBit(m int, positions ...int) error {
+	for _, p := range positions {
+		if !IsBitSet(b.playable(), p) {
+			return fmt.Errorf("bitboard: square %d is not playable", p)
+		}
+	}
+	for _, p := range positions {
+		b.placePieceBit(m, p)
+	}
+	return nil
 }
 
 // Remove the piece at algebraic coordinate p.
@@ -167,6 +867,7 @@ func (b *Bitboard) RemovePieceBit(m int, p int) {
 	// Update the occupancy bitmap.
 	ClearBit(&b.Occupied, p)
 	ClearBit(&b.Bitmaps[m], p)
+	b.counts = nil
 }
 
 // Remove the piece at Cartesian coordinates (x, y).
@@ -177,21 +878,71 @@ func (b *Bitboard) RemovePieceCartesian(m int, x int, y int) {
 
 // New constructs a new Bitboard.
 func New(ranks int, files int) (b *Bitboard, err error) {
-	b = &Bitboard{}
+	b = &Bitboard{EnPassant: -1}
 	if ranks < 0 {
 		err = errors.New("bitboard: number of ranks must be greater than zero")
 	}
 	if files < 0 {
 		err = errors.New("bitboard: number of files must be greater than zero")
 	}
-	if ranks*files > 64 {
-		err = errors.New("bitboard: bitboards cannot be larger than 64 squares")
+	if ranks*files > MaxSquares {
+		err = fmt.Errorf("bitboard: bitboards cannot be larger than %d squares", MaxSquares)
 	}
 	b.Ranks = ranks
 	b.Files = files
 	return
 }
 
+// FromGrid constructs a Bitboard from a matrix of bitmap indices like the one
+// returned by Grid, with -1 marking empty squares. The number of ranks and
+// files is taken from the shape of the matrix.
+func FromGrid(grid [][]int, symbols []string) (*Bitboard, error) {
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return nil, errors.New("bitboard: grid must not be empty")
+	}
+	b, err := New(len(grid), len(grid[0]))
+	if err != nil {
+		return nil, err
+	}
+	b.Bitmaps = make([]uint64, len(symbols))
+	b.Symbols = symbols
+	for y, row := range grid {
+		if len(row) != b.Files {
+			return nil, fmt.Errorf("bitboard: grid row %d has %d files, expected %d", y, len(row), b.Files)
+		}
+		for x, i := range row {
+			if i < -1 || i >= len(symbols) {
+				return nil, fmt.Errorf("bitboard: invalid bitmap index %d at (%d, %d)", i, x, y)
+			}
+			if i == -1 {
+				continue
+			}
+			if err := b.PlacePieceCartesian(i, x, y); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// NewMultiplayerBoard constructs an empty board for a game with any number of
+// players, each with one bitmap represented by the corresponding symbol.
+func NewMultiplayerBoard(ranks int, files int, players int, symbols []string) (*Bitboard, error) {
+	if players < 1 {
+		return nil, errors.New("bitboard: number of players must be greater than zero")
+	}
+	if len(symbols) != players {
+		return nil, fmt.Errorf("bitboard: expected %d symbols, got %d", players, len(symbols))
+	}
+	b, err := New(ranks, files)
+	if err != nil {
+		return nil, err
+	}
+	b.Bitmaps = make([]uint64, players)
+	b.Symbols = append([]string(nil), symbols...)
+	return b, nil
+}
+
 // NewChessBoard is a convenience function for constructing a new chess board.
 func NewChessBoard() *Bitboard {
 	bitmaps := []uint64{
@@ -214,11 +965,14 @@ func NewChessBoard() *Bitboard {
 	}
 	occupied := Union(bitmaps...)
 	return &Bitboard{
-		Bitmaps:  bitmaps,
-		Symbols:  symbols,
-		Occupied: occupied,
-		Ranks:    8,
-		Files:    8,
+		Bitmaps:        bitmaps,
+		Symbols:        symbols,
+		Occupied:       occupied,
+		Ranks:          8,
+		Files:          8,
+		EnPassant:      -1,
+		CastlingRights: WhiteKingside | WhiteQueenside | BlackKingside | BlackQueenside,
+		FullmoveNumber: 1,
 	}
 }
 
@@ -232,11 +986,12 @@ func NewCheckersBoard() *Bitboard {
 	symbols := []string{"R", "W"}
 	occupied := Union(bitmaps...)
 	return &Bitboard{
-		Bitmaps:  bitmaps,
-		Symbols:  symbols,
-		Occupied: occupied,
-		Ranks:    8,
-		Files:    8,
+		Bitmaps:   bitmaps,
+		Symbols:   symbols,
+		Occupied:  occupied,
+		Ranks:     8,
+		Files:     8,
+		EnPassant: -1,
 	}
 }
 
@@ -252,11 +1007,12 @@ func NewOthelloBoard() *Bitboard {
 	symbols := []string{"B", "W"}
 	occupied := Union(bitmaps...)
 	return &Bitboard{
-		Bitmaps:  bitmaps,
-		Symbols:  symbols,
-		Occupied: occupied,
-		Ranks:    8,
-		Files:    8,
+		Bitmaps:   bitmaps,
+		Symbols:   symbols,
+		Occupied:  occupied,
+		Ranks:     8,
+		Files:     8,
+		EnPassant: -1,
 	}
 }
 
@@ -270,11 +1026,12 @@ func NewReversiBoard() *Bitboard {
 	symbols := []string{"B", "W"}
 	occupied := Union(bitmaps...)
 	return &Bitboard{
-		Bitmaps:  bitmaps,
-		Symbols:  symbols,
-		Occupied: occupied,
-		Ranks:    8,
-		Files:    8,
+		Bitmaps:   bitmaps,
+		Symbols:   symbols,
+		Occupied:  occupied,
+		Ranks:     8,
+		Files:     8,
+		EnPassant: -1,
 	}
 }
 
@@ -288,11 +1045,12 @@ func NewTicTacToeBoard() *Bitboard {
 	symbols := []string{"X", "O"}
 	occupied := Union(bitmaps...)
 	return &Bitboard{
-		Bitmaps:  bitmaps,
-		Symbols:  symbols,
-		Occupied: occupied,
-		Ranks:    3,
-		Files:    3,
+		Bitmaps:   bitmaps,
+		Symbols:   symbols,
+		Occupied:  occupied,
+		Ranks:     3,
+		Files:     3,
+		EnPassant: -1,
 	}
 }
 
@@ -306,10 +1064,34 @@ func NewConnectFourBoard() *Bitboard {
 	symbols := []string{"R", "Y"}
 	occupied := Union(bitmaps...)
 	return &Bitboard{
-		Bitmaps:  bitmaps,
-		Symbols:  symbols,
-		Occupied: occupied,
-		Ranks:    6,
-		Files:    7,
+		Bitmaps:   bitmaps,
+		Symbols:   symbols,
+		Occupied:  occupied,
+		Ranks:     6,
+		Files:     7,
+		EnPassant: -1,
+	}
+}
+
+// NewGomokuBoard is a convenience function for constructing a new Gomoku
+// (five-in-a-row) board.
+//
+// Gomoku is traditionally played on a 15x15 board, but a Bitboard cannot hold
+// more than 64 squares, so this board is 8x8. Use HasLine(m, 5) to check for
+// a win.
+func NewGomokuBoard() *Bitboard {
+	bitmaps := []uint64{
+		uint64(0x0000000000000000), // Black
+		uint64(0x0000000000000000), // White
+	}
+	symbols := []string{"B", "W"}
+	occupied := Union(bitmaps...)
+	return &Bitboard{
+		Bitmaps:   bitmaps,
+		Symbols:   symbols,
+		Occupied:  occupied,
+		Ranks:     8,
+		Files:     8,
+		EnPassant: -1,
 	}
 }
@@ -0,0 +1,989 @@
+package bitboard
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSymbolAt(t *testing.T) {
+	b := NewChessBoard()
+	expected := map[int]string{
+		0:  "R",
+		4:  "K",
+		12: "P",
+		28: "",
+		52: "p",
+		59: "q",
+	}
+	for p, s := range expected {
+		result := b.SymbolAt(p)
+		if result != s {
+			t.Error("Expected", s, ", got", result)
+		}
+	}
+}
+
+func TestSymbolAtAlgebraic(t *testing.T) {
+	b := NewChessBoard()
+	expected := map[string]string{
+		"a1": "R",
+		"g1": "N",
+		"e4": "",
+		"e8": "k",
+	}
+	for p, s := range expected {
+		result := b.SymbolAtAlgebraic(p)
+		if result != s {
+			t.Error("Expected", s, ", got", result)
+		}
+	}
+}
+
+func TestSymbolAtCartesian(t *testing.T) {
+	b := NewTicTacToeBoard()
+	b.PlacePieceCartesian(0, 1, 1)
+	b.PlacePieceCartesian(1, 2, 0)
+	if s := b.SymbolAtCartesian(1, 1); s != "X" {
+		t.Error("Expected X, got", s)
+	}
+	if s := b.SymbolAtCartesian(2, 0); s != "O" {
+		t.Error("Expected O, got", s)
+	}
+	if s := b.SymbolAtCartesian(0, 2); s != "" {
+		t.Error("Expected empty symbol, got", s)
+	}
+}
+
+func TestSetSymbols(t *testing.T) {
+	b := NewChessBoard()
+	glyphs := strings.Split("♖ ♘ ♗ ♕ ♔ ♙ ♜ ♞ ♝ ♛ ♚ ♟", " ")
+	if err := b.SetSymbols(glyphs); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	var s strings.Builder
+	b.Fprint(&s, PrintOptions{})
+	lines := strings.Split(s.String(), "\n")
+	if lines[0] != "♜♞♝♛♚♝♞♜" || lines[7] != "♖♘♗♕♔♗♘♖" {
+		t.Errorf("Expected Unicode glyphs, got %q", s.String())
+	}
+	// The board keeps its own copy of the symbols.
+	glyphs[0] = "R"
+	if b.SymbolAtAlgebraic("a1") != "♖" {
+		t.Error("Expected ♖, got", b.SymbolAtAlgebraic("a1"))
+	}
+	if err := b.SetSymbols([]string{"X", "O"}); err == nil {
+		t.Error("Expected error for too few symbols")
+	}
+	if b.SymbolAtAlgebraic("e8") != "♚" {
+		t.Error("Expected symbols to be unchanged, got", b.SymbolAtAlgebraic("e8"))
+	}
+}
+
+func TestBitmapFor(t *testing.T) {
+	b := NewChessBoard()
+	if bitmap, ok := b.BitmapFor("P"); !ok || bitmap != 0xff00 {
+		t.Errorf("Expected %#016x, got %#016x", 0xff00, bitmap)
+	}
+	if bitmap, ok := b.BitmapFor("k"); !ok || bitmap != 1<<60 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1)<<60, bitmap)
+	}
+	if _, ok := b.BitmapFor("X"); ok {
+		t.Error("Expected no bitmap for X")
+	}
+}
+
+func TestSquareOf(t *testing.T) {
+	b := NewChessBoard()
+	if p, ok := b.SquareOf(BlackKing); !ok || p != 60 {
+		t.Error("Expected 60, got", p, ok)
+	}
+	if p, ok := b.SquareOf(WhitePawns); ok {
+		t.Error("Expected no unique square, got", p)
+	}
+	b.RemovePieceAlgebraic(WhiteQueen, "d1")
+	if p, ok := b.SquareOf(WhiteQueen); ok {
+		t.Error("Expected no unique square, got", p)
+	}
+}
+
+func TestSetBits(t *testing.T) {
+	b := NewChessBoard()
+	if result := b.SetBits(BlackKnights); !reflect.DeepEqual(result, []int{57, 62}) {
+		t.Error("Expected [57 62], got", result)
+	}
+}
+
+func TestRecomputeOccupied(t *testing.T) {
+	b := NewChessBoard()
+	expected := b.Occupied
+	b.Occupied = 0xdeadbeef
+	b.RecomputeOccupied()
+	if b.Occupied != expected {
+		t.Errorf("Expected %#x, got %#x", expected, b.Occupied)
+	}
+	// Direct changes to bitmaps leave Occupied stale until repaired.
+	SetBit(&b.Bitmaps[5], 28)
+	b.RecomputeOccupied()
+	if GetBit(&b.Occupied, 28) != 1 {
+		t.Error("Expected square 28 to be occupied")
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	b := NewChessBoard()
+	if err := b.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	// Stale occupancy.
+	b.Occupied = 0
+	if err := b.CheckInvariants(); err == nil {
+		t.Error("Expected error for stale occupancy bitmap")
+	}
+	b.RecomputeOccupied()
+	if err := b.CheckInvariants(); err != nil {
+		t.Error("Expected no error after repair, got", err)
+	}
+	// Overlapping bitmaps.
+	SetBit(&b.Bitmaps[0], 8)
+	b.RecomputeOccupied()
+	if err := b.CheckInvariants(); err == nil {
+		t.Error("Expected error for overlapping bitmaps")
+	}
+	// A bitmap without a symbol.
+	b = NewChessBoard()
+	b.Symbols = b.Symbols[:11]
+	if err := b.CheckInvariants(); err == nil {
+		t.Error("Expected error for mismatched symbols")
+	}
+}
+
+func TestNewMaxSquares(t *testing.T) {
+	var tests = []struct {
+		ranks int
+		files int
+		ok    bool
+	}{
+		{8, 8, true},
+		{4, 16, true},
+		{1, MaxSquares, true},
+		{5, 13, false},
+		{1, MaxSquares + 1, false},
+	}
+	for _, test := range tests {
+		_, err := New(test.ranks, test.files)
+		if (err == nil) != test.ok {
+			t.Error("Expected ok", test.ok, "for", test.ranks, "x", test.files, ", got", err)
+		}
+	}
+}
+
+func TestHasLine(t *testing.T) {
+	b := NewGomokuBoard()
+	for _, p := range []string{"b2", "c3", "d4", "e5"} {
+		b.PlacePieceAlgebraic(0, p)
+	}
+	if b.HasLine(0, 5) {
+		t.Error("Expected no line of five")
+	}
+	if !b.HasLine(0, 4) {
+		t.Error("Expected a line of four")
+	}
+	b.PlacePieceAlgebraic(0, "f6")
+	if !b.HasLine(0, 5) {
+		t.Error("Expected a diagonal line of five")
+	}
+	if b.HasLine(1, 5) {
+		t.Error("Expected no line of five for White")
+	}
+	// Lines do not wrap around the edge of the board.
+	for _, p := range []string{"e1", "f1", "g1", "h1", "a2"} {
+		b.PlacePieceAlgebraic(1, p)
+	}
+	if b.HasLine(1, 5) {
+		t.Error("Expected no line of five for White")
+	}
+}
+
+func TestWinner(t *testing.T) {
+	b := NewTicTacToeBoard()
+	if result := b.Winner(3); result != -1 {
+		t.Error("Expected -1, got", result)
+	}
+	b.PlacePieces(1, "a1", "b2", "c3")
+	if result := b.Winner(3); result != 1 {
+		t.Error("Expected 1, got", result)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before, _ := NewChessBoardFromMoves([]string{"e2e4", "d7d5"})
+	after, _ := NewChessBoardFromMoves([]string{"e2e4", "d7d5", "e4d5"})
+	added, removed, err := before.Diff(after)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	d5 := before.AlgebraicToBit("d5")
+	e4 := before.AlgebraicToBit("e4")
+	if len(added) != 1 || added[d5] != WhitePawns {
+		t.Error("Expected white pawn added on d5, got", added)
+	}
+	if len(removed) != 2 || removed[e4] != WhitePawns || removed[d5] != BlackPawns {
+		t.Error("Expected pawns removed from e4 and d5, got", removed)
+	}
+	added, removed, _ = after.Diff(after)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Error("Expected no differences, got", added, removed)
+	}
+	if _, _, err := before.Diff(NewTicTacToeBoard()); err == nil {
+		t.Error("Expected error for mismatched dimensions")
+	}
+}
+
+func TestFprint(t *testing.T) {
+	var s strings.Builder
+	b := NewTicTacToeBoard()
+	b.PlacePieceAlgebraic(0, "b2")
+	b.PlacePieceAlgebraic(1, "a1")
+	if err := b.Fprint(&s, PrintOptions{}); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := "...\n.X.\nO..\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+}
+
+func TestFprintLayer(t *testing.T) {
+	var s strings.Builder
+	b := NewChessBoard()
+	b.MovePieceAlgebraic(WhitePawns, "e2", "e4")
+	if err := b.FprintLayer(&s, WhitePawns); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := "........\n" +
+		"........\n" +
+		"........\n" +
+		"........\n" +
+		"....P...\n" +
+		"........\n" +
+		"PPPP.PPP\n" +
+		"........\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+	// Unplayable squares are shown as empty.
+	s.Reset()
+	c := NewCheckersBoard()
+	c.FprintLayer(&s, 0)
+	if !strings.HasPrefix(s.String(), ".R.R.R.R\nR.R.R.R.\n") {
+		t.Errorf("Expected only Red's pieces, got %q", s.String())
+	}
+}
+
+func TestFprintHighlight(t *testing.T) {
+	var s strings.Builder
+	b, _ := ParseFEN("8/8/8/8/3N4/8/8/8 w")
+	d4 := b.AlgebraicToBit("d4")
+	opts := PrintOptions{Highlight: KnightAttacks(d4)}
+	if err := b.Fprint(&s, opts); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := "........\n" +
+		"........\n" +
+		"..*.*...\n" +
+		".*...*..\n" +
+		"...N....\n" +
+		".*...*..\n" +
+		"..*.*...\n" +
+		"........\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+	s.Reset()
+	SetBit(&opts.Highlight, d4)
+	b.Fprint(&s, opts)
+	if !strings.Contains(s.String(), ".  .  .  [N].  .  .  .") {
+		t.Errorf("Expected bracketed knight, got %q", s.String())
+	}
+}
+
+func TestFprintSeparator(t *testing.T) {
+	var s strings.Builder
+	b := NewTicTacToeBoard()
+	b.PlacePieceAlgebraic(0, "b2")
+	b.PlacePieceAlgebraic(1, "a1")
+	opts := PrintOptions{EmptySymbol: "-", CellSeparator: "  "}
+	if err := b.Fprint(&s, opts); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := "-  -  -\n-  X  -\nO  -  -\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+	// Wider symbols pad every column to the same width.
+	s.Reset()
+	b.Symbols = []string{"XX", "Ø"}
+	b.Fprint(&s, opts)
+	expected = "-   -   -\n-   XX  -\nØ   -   -\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+	// Wide characters take up two columns, and combining marks none.
+	s.Reset()
+	b.Symbols = []string{"王", "e\u0301"}
+	b.Fprint(&s, opts)
+	expected = "-   -   -\n-   王  -\ne\u0301   -   -\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	var tests = []struct {
+		s        string
+		expected int
+	}{
+		{"", 0},
+		{"K", 1},
+		{"WK", 2},
+		{"♔", 1},
+		{"王", 2},
+		{"🐴", 2},
+		{"e\u0301", 1},
+	}
+	for _, test := range tests {
+		if result := displayWidth(test.s); result != test.expected {
+			t.Error("Expected", test.expected, "for", test.s, ", got", result)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	b := NewTicTacToeBoard()
+	b.PlacePieceAlgebraic(0, "a1")
+	b.PlacePieceAlgebraic(1, "b2")
+	b.PlacePieceAlgebraic(0, "c3")
+	b.PlacePieceAlgebraic(1, "c1")
+	expected := [][]int{
+		{0, -1, 1},
+		{-1, 1, -1},
+		{-1, -1, 0},
+	}
+	grid := b.Grid()
+	if len(grid) != len(expected) {
+		t.Fatal("Expected", len(expected), "rows, got", len(grid))
+	}
+	for y := range expected {
+		for x := range expected[y] {
+			if grid[y][x] != expected[y][x] {
+				t.Error("Expected", expected[y][x], "at", x, y, ", got", grid[y][x])
+			}
+		}
+	}
+	grid = NewConnectFourBoard().Grid()
+	if len(grid) != 6 || len(grid[0]) != 7 {
+		t.Error("Expected 6x7 grid, got", len(grid), "x", len(grid[0]))
+	}
+}
+
+func TestPieces(t *testing.T) {
+	pieces := NewChessBoard().Pieces()
+	if len(pieces) != 12 {
+		t.Error("Expected 12 symbols, got", len(pieces))
+	}
+	expected := []string{"a2", "b2", "c2", "d2", "e2", "f2", "g2", "h2"}
+	if !reflect.DeepEqual(pieces["P"], expected) {
+		t.Error("Expected", expected, ", got", pieces["P"])
+	}
+	expected = []string{"b8", "g8"}
+	if !reflect.DeepEqual(pieces["n"], expected) {
+		t.Error("Expected", expected, ", got", pieces["n"])
+	}
+	if pieces := NewTicTacToeBoard().Pieces(); len(pieces) != 0 {
+		t.Error("Expected no pieces, got", pieces)
+	}
+}
+
+func TestScroll(t *testing.T) {
+	// A glider in the top-left corner of a Connect Four board.
+	b := NewConnectFourBoard()
+	b.PlacePieces(0, "b6", "c5", "a4", "b4", "c4")
+	b.Scroll(1, -1, false)
+	if result := b.Pieces()["R"]; !reflect.DeepEqual(result, []string{"b3", "c3", "d3", "d4", "c5"}) {
+		t.Error("Expected glider to move down and right, got", result)
+	}
+	// Scrolling up pushes the top row off the board.
+	b.Scroll(0, 2, false)
+	if result := b.Pieces()["R"]; !reflect.DeepEqual(result, []string{"b5", "c5", "d5", "d6"}) {
+		t.Error("Expected top row to be dropped, got", result)
+	}
+	b.Scroll(0, 3, false)
+	if b.Occupied != 0 || b.Bitmaps[0] != 0 {
+		t.Errorf("Expected empty board, got %#016x", b.Occupied)
+	}
+	// Pieces on the right-hand file do not wrap onto the next rank.
+	b.PlacePieces(1, "g1")
+	b.Scroll(1, 0, false)
+	if b.Occupied != 0 {
+		t.Errorf("Expected empty board, got %#016x", b.Occupied)
+	}
+	// Unless asked to.
+	b.PlacePieces(1, "g1", "a6")
+	b.Scroll(1, 1, true)
+	if result := b.Pieces()["Y"]; !reflect.DeepEqual(result, []string{"b1", "a2"}) {
+		t.Error("Expected pieces to wrap, got", result)
+	}
+	b.Scroll(-1, -1, true)
+	if result := b.Pieces()["Y"]; !reflect.DeepEqual(result, []string{"g1", "a6"}) {
+		t.Error("Expected pieces to wrap back, got", result)
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	// The four corners balance at the centre of the board.
+	b := NewTicTacToeBoard()
+	b.PlacePieces(0, "a1", "c1", "a3", "c3")
+	if x, y, ok := b.Centroid(0); !ok || x != 1 || y != 1 {
+		t.Error("Expected (1, 1), got", x, y, ok)
+	}
+	if x, y, ok := NewChessBoard().Centroid(WhitePawns); !ok || x != 3.5 || y != 1 {
+		t.Error("Expected (3.5, 1), got", x, y, ok)
+	}
+	b.PlacePieceAlgebraic(1, "b3")
+	if x, y, ok := b.Centroid(1); !ok || x != 1 || y != 2 {
+		t.Error("Expected (1, 2), got", x, y, ok)
+	}
+	if _, _, ok := NewTicTacToeBoard().Centroid(0); ok {
+		t.Error("Expected no centroid for an empty bitmap")
+	}
+}
+
+func TestFromGrid(t *testing.T) {
+	b := NewTicTacToeBoard()
+	b.PlacePieceAlgebraic(0, "a1")
+	b.PlacePieceAlgebraic(1, "b2")
+	b.PlacePieceAlgebraic(0, "c3")
+	c, err := FromGrid(b.Grid(), b.Symbols)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if c.Ranks != b.Ranks || c.Files != b.Files {
+		t.Error("Expected", b.Ranks, b.Files, ", got", c.Ranks, c.Files)
+	}
+	for i := range b.Bitmaps {
+		if c.Bitmaps[i] != b.Bitmaps[i] {
+			t.Errorf("Expected bitmap %d to be %#x, got %#x", i, b.Bitmaps[i], c.Bitmaps[i])
+		}
+	}
+	if c.Occupied != b.Occupied {
+		t.Errorf("Expected %#x, got %#x", b.Occupied, c.Occupied)
+	}
+	invalid := [][][]int{
+		{},
+		{{0, -1}, {1}},    // ragged
+		{{0, 2}, {1, -1}}, // index out of range
+		{{-2, 0}},         // index out of range
+		make([][]int, 9),  // too large once filled
+	}
+	for i := range invalid[4] {
+		invalid[4][i] = make([]int, 8)
+	}
+	for _, grid := range invalid {
+		if _, err := FromGrid(grid, []string{"X", "O"}); err == nil {
+			t.Error("Expected error for", grid)
+		}
+	}
+}
+
+func TestLiberties(t *testing.T) {
+	b := NewGomokuBoard()
+	b.PlacePieceAlgebraic(0, "d4")
+	if result := b.Liberties(0, b.AlgebraicToBit("d4")); result != 4 {
+		t.Error("Expected 4, got", result)
+	}
+	// Edges and corners reduce liberties.
+	b.PlacePieceAlgebraic(0, "a1")
+	if result := b.Liberties(0, b.AlgebraicToBit("a1")); result != 2 {
+		t.Error("Expected 2, got", result)
+	}
+	b.PlacePieceAlgebraic(0, "h5")
+	if result := b.Liberties(0, b.AlgebraicToBit("h5")); result != 3 {
+		t.Error("Expected 3, got", result)
+	}
+	// A group of d4, e4, and e5 shares liberties; opposing stones take them.
+	b.PlacePieceAlgebraic(0, "e4")
+	b.PlacePieceAlgebraic(0, "e5")
+	b.PlacePieceAlgebraic(1, "d5")
+	b.PlacePieceAlgebraic(1, "f4")
+	for _, p := range []string{"d4", "e4", "e5"} {
+		if result := b.Liberties(0, b.AlgebraicToBit(p)); result != 5 {
+			t.Error("Expected 5 liberties for", p, ", got", result)
+		}
+	}
+	if result := b.Liberties(1, b.AlgebraicToBit("d5")); result != 2 {
+		t.Error("Expected 2, got", result)
+	}
+	if result := b.Liberties(0, b.AlgebraicToBit("c3")); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+}
+
+func TestPlacePieces(t *testing.T) {
+	b, _ := New(8, 8)
+	b.Bitmaps = make([]uint64, 1)
+	b.Symbols = []string{"P"}
+	err := b.PlacePieces(0, "a2", "b2", "c2", "d2", "e2", "f2", "g2", "h2")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if b.Bitmaps[0] != 0xff00 || b.Occupied != 0xff00 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0xff00), b.Bitmaps[0])
+	}
+	// A bad coordinate is reported and nothing is placed.
+	if err := b.PlacePieces(0, "a3", "i3"); err == nil {
+		t.Error("Expected error for i3")
+	}
+	if err := b.PlacePiecesBit(0, 16, 64); err == nil {
+		t.Error("Expected error for bit 64")
+	}
+	if b.Occupied != 0xff00 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0xff00), b.Occupied)
+	}
+}
+
+func TestPlacePieceAlgebraic(t *testing.T) {
+	b := NewTicTacToeBoard()
+	if err := b.PlacePieceAlgebraic(0, "B2"); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if err := b.MovePieceAlgebraic(0, "b2", "C3"); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if b.Bitmaps[0] != 1<<8 {
+		t.Errorf("Expected %#x, got %#x", 1<<8, b.Bitmaps[0])
+	}
+	// Coordinates off the board are rejected rather than misplaced.
+	for _, p := range []string{"d1", "a4", "z"} {
+		if err := b.PlacePieceAlgebraic(0, p); err == nil {
+			t.Error("Expected error for", p)
+		}
+		if err := b.MovePieceAlgebraic(0, "c3", p); err == nil {
+			t.Error("Expected error for", p)
+		}
+	}
+	if b.Bitmaps[0] != 1<<8 {
+		t.Errorf("Expected %#x, got %#x", 1<<8, b.Bitmaps[0])
+	}
+}
+
+func TestMovePieceBit(t *testing.T) {
+	b := NewTicTacToeBoard()
+	b.PlacePieceBit(0, 0)
+	b.PlacePieceBit(0, 4)
+	// Moving a piece to its own square leaves the board unchanged.
+	if err := b.MovePieceBit(0, 4, 4); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if b.Bitmaps[0] != 0x011 || b.Occupied != 0x011 {
+		t.Errorf("Expected %#x, got %#x", 0x011, b.Occupied)
+	}
+	// Nor does it conjure a piece on an empty square.
+	b.MovePieceBit(0, 8, 8)
+	if b.Occupied != 0x011 {
+		t.Errorf("Expected %#x, got %#x", 0x011, b.Occupied)
+	}
+	// Moving onto a square held by the same bitmap is refused.
+	if err := b.MovePieceBit(0, 0, 4); err == nil {
+		t.Error("Expected error")
+	}
+	if b.Bitmaps[0] != 0x011 || b.Occupied != 0x011 {
+		t.Errorf("Expected %#x, got %#x", 0x011, b.Occupied)
+	}
+	if err := b.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	b := NewChessBoard()
+	c := b.Clone()
+	c.MovePieceAlgebraic(WhitePawns, "e2", "e4")
+	c.Symbols[0] = "♖"
+	if b.SymbolAtAlgebraic("e2") != "P" || b.SymbolAtAlgebraic("e4") != "" {
+		t.Error("Expected original board to be unchanged")
+	}
+	if b.Symbols[0] != "R" {
+		t.Error("Expected original symbols to be unchanged")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	b := NewTicTacToeBoard()
+	b.PlacePieces(0, "a1", "b2")
+	b.PlacePieceAlgebraic(1, "c3")
+	s := b.Snapshot()
+	b.MovePieceAlgebraic(0, "a1", "a3")
+	b.RemovePieceAlgebraic(1, "c3")
+	b.PlacePieceAlgebraic(1, "c1")
+	b.SetToMove(1)
+	b.Restore(s)
+	if b.Bitmaps[0] != 0x011 || b.Bitmaps[1] != 0x100 || b.Occupied != 0x111 {
+		t.Errorf("Expected %#03x, got %#03x", 0x111, b.Occupied)
+	}
+	if b.ToMove() != 0 {
+		t.Error("Expected 0, got", b.ToMove())
+	}
+	// The snapshot is unaffected by changes after it is restored.
+	b.RemovePieceAlgebraic(0, "b2")
+	b.Restore(s)
+	if b.Occupied != 0x111 {
+		t.Errorf("Expected %#03x, got %#03x", 0x111, b.Occupied)
+	}
+}
+
+func TestFlipped(t *testing.T) {
+	b := NewChessBoard()
+	b.RemovePieceAlgebraic(WhiteRooks, "h1")
+	f := b.Flipped()
+	if f.SymbolAtAlgebraic("h8") != "R" || f.SymbolAtAlgebraic("a8") != "" {
+		t.Error("Expected rook on a1 to land on h8")
+	}
+	if f.SymbolAtAlgebraic("d8") != "K" || f.SymbolAtAlgebraic("e1") != "q" {
+		t.Error("Expected kings and queens to swap files")
+	}
+	if err := f.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if f.Occupied != Rotate180(b.Occupied) {
+		t.Errorf("Expected %#016x, got %#016x", Rotate180(b.Occupied), f.Occupied)
+	}
+	// Boards of other sizes rotate about their own centre.
+	c := NewConnectFourBoard()
+	c.PlacePieceAlgebraic(0, "a1")
+	c.PlacePieceAlgebraic(1, "c2")
+	f = c.Flipped()
+	if f.SymbolAtAlgebraic("g6") != "R" || f.SymbolAtAlgebraic("e5") != "Y" {
+		t.Error("Expected pieces to rotate on a 6x7 board")
+	}
+	if PopCount(f.Occupied) != 2 {
+		t.Error("Expected 2 pieces, got", PopCount(f.Occupied))
+	}
+}
+
+func TestCounts(t *testing.T) {
+	b := NewChessBoard()
+	expected := []int{2, 2, 2, 1, 1, 8, 2, 2, 2, 1, 1, 8}
+	if result := b.Counts(); !reflect.DeepEqual(result, expected) {
+		t.Error("Expected", expected, ", got", result)
+	}
+	// 1. e4 d5 2. exd5 Qxd5
+	for _, s := range []string{"e4", "d5", "exd5", "Qxd5"} {
+		from, to, m, err := b.ParseSAN(s, b.SideToMove)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		b.MakeMove(Move{From: from, To: to, Piece: m})
+		b.Counts()
+	}
+	expected = []int{2, 2, 2, 1, 1, 7, 2, 2, 2, 1, 1, 7}
+	if result := b.Counts(); !reflect.DeepEqual(result, expected) {
+		t.Error("Expected", expected, ", got", result)
+	}
+	if result := b.MaterialBalance(); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+	b.RemovePieceAlgebraic(BlackQueen, "d5")
+	if result := b.MaterialBalance(); result != 900 {
+		t.Error("Expected 900, got", result)
+	}
+	// Modifying the returned counts does not corrupt the cache.
+	b.Counts()[WhitePawns] = 0
+	if result := b.Counts()[WhitePawns]; result != 7 {
+		t.Error("Expected 7, got", result)
+	}
+	// Bitmaps modified directly are counted after RecomputeOccupied.
+	b.Bitmaps[WhiteQueen] = 0
+	b.RecomputeOccupied()
+	if result := b.MaterialBalance(); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+}
+
+func TestAlgebraicFlipped(t *testing.T) {
+	b := NewChessBoard()
+	var tests = []struct {
+		p        int
+		expected string
+	}{
+		{0, "h8"},
+		{63, "a1"},
+		{12, "d7"},
+		{28, "d5"},
+	}
+	for _, test := range tests {
+		if result := b.BitToAlgebraicFlipped(test.p); result != test.expected {
+			t.Error("Expected", test.expected, ", got", result)
+		}
+		if result := b.AlgebraicToBitFlipped(test.expected); result != test.p {
+			t.Error("Expected", test.p, ", got", result)
+		}
+	}
+	// The labels agree with the pieces on the flipped board.
+	f := b.Flipped()
+	if f.SymbolAtAlgebraic(b.BitToAlgebraicFlipped(4)) != "K" {
+		t.Error("Expected the white king under its flipped label")
+	}
+	c := NewConnectFourBoard()
+	if result := c.BitToAlgebraicFlipped(0); result != "g6" {
+		t.Error("Expected g6, got", result)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	b := NewChessBoard()
+	b.Bitmaps = make([]uint64, 12)
+	b.RecomputeOccupied()
+	b.PlacePieceAlgebraic(WhiteKnights, "b1")
+	b.PlacePieceAlgebraic(BlackKing, "c2")
+	var tests = []struct {
+		name     string
+		expected string
+		f        func(uint64) uint64
+	}{
+		{"flipv", "b8", FlipVertical},
+		{"fliph", "g1", FlipHorizontal},
+		{"rot90", "a7", Rotate90},
+		{"rot180", "g8", Rotate180},
+		{"rot270", "h2", Rotate270},
+		{"diaga1h8", "a2", FlipDiagonalA1H8},
+		{"diaga8h1", "h7", FlipDiagonalA8H1},
+	}
+	for _, test := range tests {
+		c, err := b.Transform(test.name)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if result := c.Pieces()["N"]; len(result) != 1 || result[0] != test.expected {
+			t.Error("Expected", test.name, "to move the knight to", test.expected, ", got", result)
+		}
+		if c.Occupied != test.f(b.Occupied) {
+			t.Errorf("Expected %#016x, got %#016x", test.f(b.Occupied), c.Occupied)
+		}
+	}
+	// Rotating a Connect Four board lays it on its side.
+	c := NewConnectFourBoard()
+	c.PlacePieceAlgebraic(0, "a1")
+	r, _ := c.Transform("rot90")
+	if r.Files != 6 || r.Ranks != 7 || r.SymbolAtAlgebraic("a7") != "R" {
+		t.Error("Expected a 6x7 board with the piece on a7")
+	}
+	if _, err := b.Transform("spin"); err == nil {
+		t.Error("Expected error for unknown transform")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	b := NewTicTacToeBoard()
+	b.PlacePieceAlgebraic(0, "a1")
+	b.PlacePieceAlgebraic(1, "b1")
+	b.PlacePieceAlgebraic(0, "b2")
+	canonical := b.Canonical()
+	for _, name := range symmetries {
+		c, _ := b.Transform(name)
+		if result := c.Canonical(); !reflect.DeepEqual(result.Bitmaps, canonical.Bitmaps) {
+			t.Error("Expected", canonical.Bitmaps, "for", name, ", got", result.Bitmaps)
+		}
+	}
+	// A different position has a different canonical form.
+	b.RemovePieceAlgebraic(1, "b1")
+	b.PlacePieceAlgebraic(1, "c2")
+	if result := b.Canonical(); reflect.DeepEqual(result.Bitmaps, canonical.Bitmaps) {
+		t.Error("Expected a different canonical form, got", result.Bitmaps)
+	}
+	// Rectangular boards are only flipped and turned around.
+	c := NewConnectFourBoard()
+	c.PlacePieceAlgebraic(0, "g6")
+	if result := c.Canonical(); result.Files != 7 || result.SymbolAtAlgebraic("a1") != "R" {
+		t.Error("Expected the piece in the corner of a 6x7 board")
+	}
+}
+
+func TestSymmetryGroup(t *testing.T) {
+	b := NewTicTacToeBoard()
+	if result := b.SymmetryGroup(); !reflect.DeepEqual(result, symmetries) {
+		t.Error("Expected", symmetries, ", got", result)
+	}
+	// A single piece in the centre is fully symmetric.
+	b.PlacePieceAlgebraic(0, "b2")
+	if result := b.SymmetryGroup(); !reflect.DeepEqual(result, symmetries) {
+		t.Error("Expected", symmetries, ", got", result)
+	}
+	// Adding one on the middle of the bottom rank leaves only the flip
+	// about the vertical axis.
+	b.PlacePieceAlgebraic(1, "b1")
+	expected := []string{"identity", "fliph"}
+	if result := b.SymmetryGroup(); !reflect.DeepEqual(result, expected) {
+		t.Error("Expected", expected, ", got", result)
+	}
+	// The starting position of chess is not symmetric at all.
+	expected = []string{"identity"}
+	if result := NewChessBoard().SymmetryGroup(); !reflect.DeepEqual(result, expected) {
+		t.Error("Expected", expected, ", got", result)
+	}
+}
+
+func TestNewMultiplayerBoard(t *testing.T) {
+	b, err := NewMultiplayerBoard(9, 7, 3, []string{"R", "G", "B"})
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if len(b.Bitmaps) != 3 || b.Ranks != 9 || b.Files != 7 {
+		t.Error("Expected three bitmaps on a 9x7 board")
+	}
+	b.PlacePieces(0, "a1", "b1", "c1")
+	b.PlacePieces(1, "a2", "b2", "c2")
+	b.PlacePieces(2, "g6", "g7", "g8")
+	if result := b.Winner(4); result != -1 {
+		t.Error("Expected -1, got", result)
+	}
+	b.PlacePieceAlgebraic(2, "g9")
+	if result := b.Winner(4); result != 2 {
+		t.Error("Expected 2, got", result)
+	}
+	if b.SymbolAtAlgebraic("g9") != "B" {
+		t.Error("Expected B, got", b.SymbolAtAlgebraic("g9"))
+	}
+	if _, err := NewMultiplayerBoard(9, 7, 3, []string{"R", "G"}); err == nil {
+		t.Error("Expected error for missing symbol")
+	}
+	if _, err := NewMultiplayerBoard(9, 8, 3, []string{"R", "G", "B"}); err == nil {
+		t.Error("Expected error for 72 squares")
+	}
+	if _, err := NewMultiplayerBoard(3, 3, 0, nil); err == nil {
+		t.Error("Expected error for no players")
+	}
+}
+
+func TestTrimToFit(t *testing.T) {
+	b, _ := ParseFEN("8/8/8/8/8/1k6/8/K1Q5 w")
+	c := b.TrimToFit()
+	if c.Ranks != 3 || c.Files != 3 {
+		t.Fatal("Expected 3x3 board, got", c.Ranks, "x", c.Files)
+	}
+	expected := map[string]string{"a1": "K", "c1": "Q", "b3": "k", "b2": ""}
+	for square, symbol := range expected {
+		if result := c.SymbolAtAlgebraic(square); result != symbol {
+			t.Error("Expected", symbol, "on", square, ", got", result)
+		}
+	}
+	if err := c.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if b.Ranks != 8 || b.SymbolAtAlgebraic("c1") != "Q" {
+		t.Error("Expected original board to be unchanged")
+	}
+	// The rectangle need not touch a corner.
+	b = NewTicTacToeBoard()
+	b.PlacePieces(0, "b2", "c2")
+	c = b.TrimToFit()
+	if c.Ranks != 1 || c.Files != 2 || c.Bitmaps[0] != 0x3 {
+		t.Error("Expected 1x2 board, got", c.Ranks, "x", c.Files)
+	}
+}
+
+func TestTopLeftOrigin(t *testing.T) {
+	b := NewChessBoard()
+	c := NewChessBoard()
+	c.TopLeftOrigin = true
+	// a8 is (0, 7) from the bottom left but (0, 0) from the top left.
+	if x, y := b.BitToCartesian(56); x != 0 || y != 7 {
+		t.Error("Expected (0, 7), got", x, y)
+	}
+	if x, y := c.BitToCartesian(56); x != 0 || y != 0 {
+		t.Error("Expected (0, 0), got", x, y)
+	}
+	if p := c.CartesianToBit(0, 0); p != 56 {
+		t.Error("Expected 56, got", p)
+	}
+	if x, y := c.AlgebraicToCartesian("e2"); x != 4 || y != 6 {
+		t.Error("Expected (4, 6), got", x, y)
+	}
+	if p := c.CartesianToAlgebraic(4, 6); p != "e2" {
+		t.Error("Expected e2, got", p)
+	}
+	// Algebraic notation and bit positions are unaffected.
+	if b.AlgebraicToBit("e2") != c.AlgebraicToBit("e2") || c.SymbolAtAlgebraic("e1") != "K" {
+		t.Error("Expected algebraic notation to be unaffected")
+	}
+	if c.SymbolAtCartesian(4, 0) != "k" || b.SymbolAtCartesian(4, 0) != "K" {
+		t.Error("Expected e8 at (4, 0) from the top left")
+	}
+	if grid := c.Grid(); grid[0][0] != BlackRooks || grid[7][4] != WhiteKing {
+		t.Error("Expected the top rank in row 0")
+	}
+	// The printed board looks the same either way.
+	var s1, s2 strings.Builder
+	b.Fprint(&s1, PrintOptions{})
+	c.Fprint(&s2, PrintOptions{})
+	if s1.String() != s2.String() {
+		t.Errorf("Expected %q, got %q", s1.String(), s2.String())
+	}
+	// ...with (0, 0) from the top left printed first.
+	if s := c.SymbolAtCartesian(0, 0); !strings.HasPrefix(s2.String(), s) {
+		t.Errorf("Expected %q first, got %q", s, s2.String())
+	}
+	c.MovePieceCartesian(WhitePawns, 4, 6, 4, 4)
+	if c.SymbolAtAlgebraic("e4") != "P" {
+		t.Error("Expected pawn to move to e4")
+	}
+}
+
+func TestPlayable(t *testing.T) {
+	b := NewTicTacToeBoard()
+	if b.Empty() != 0x1ff {
+		t.Errorf("Expected %#x, got %#x", 0x1ff, b.Empty())
+	}
+	// A cross: b1, a2, b2, c2, and b3.
+	b.Playable = 0x0ba
+	if b.Empty() != 0x0ba {
+		t.Errorf("Expected %#x, got %#x", 0x0ba, b.Empty())
+	}
+	if err := b.PlacePieceAlgebraic(0, "a1"); err == nil {
+		t.Error("Expected error placing on a1")
+	}
+	if b.Occupied != 0 {
+		t.Errorf("Expected 0, got %#x", b.Occupied)
+	}
+	if err := b.PlacePieceAlgebraic(0, "b2"); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if err := b.MovePieceAlgebraic(0, "b2", "c3"); err == nil {
+		t.Error("Expected error moving to c3")
+	}
+	if b.SymbolAtAlgebraic("b2") != "X" {
+		t.Error("Expected piece to stay on b2")
+	}
+	if err := b.MovePieceCartesian(0, 1, 1, 2, 1); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if b.Empty() != 0x09a {
+		t.Errorf("Expected %#x, got %#x", 0x09a, b.Empty())
+	}
+	var s strings.Builder
+	b.Fprint(&s, PrintOptions{})
+	expected := " . \n..X\n . \n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+	s.Reset()
+	b.Fprint(&s, PrintOptions{UnplayableSymbol: "#"})
+	expected = "#.#\n..X\n#.#\n"
+	if s.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, s.String())
+	}
+	// Liberties only count playable squares.
+	if result := b.Liberties(0, b.AlgebraicToBit("c2")); result != 1 {
+		t.Error("Expected 1, got", result)
+	}
+}
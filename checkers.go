@@ -0,0 +1,100 @@
+package bitboard
+
+// Move generation for checkers (English draughts). These assume the bitmap
+// ordering used by NewCheckersBoard: player 0 is Red, whose men move down the
+// board, and player 1 is White, whose men move up it.
+
+// CheckersMoves generates a checkers player's moves. Jumps are mandatory: if
+// any piece can jump, only jumps are returned. A jump that can continue must,
+// so each jump move runs from the start to the end of a complete sequence and
+// lists every piece jumped in the move's Jumped field. A man reaching the far
+// rank is flagged with PromoteKing, and its move ends there. Kings, marked in
+// the board's Kings bitmap, move and jump backwards as well as forwards.
+func (b *Bitboard) CheckersMoves(player int) []Move {
+	var jumps, steps []Move
+	for pieces := b.Bitmaps[player]; pieces != 0; pieces &= pieces - 1 {
+		from := LSB(pieces)
+		jumps = b.checkersJumps(jumps, player, from, from, 0)
+		x, y := BitToCartesian(from, b.Files)
+		for _, dy := range b.checkersDirections(player, from) {
+			for _, dx := range []int{-1, 1} {
+				i, j := x+dx, y+dy
+				if !b.onBoard(i, j) || IsBitSet(b.Occupied, CartesianToBit(i, j, b.Files)) {
+					continue
+				}
+				steps = append(steps, b.checkersMove(player, from, CartesianToBit(i, j, b.Files), 0))
+			}
+		}
+	}
+	if len(jumps) > 0 {
+		return jumps
+	}
+	return steps
+}
+
+// checkersJumps appends to moves every complete jump sequence that the piece
+// which started on square start can make from square p, having already jumped
+// the pieces in jumped.
+func (b *Bitboard) checkersJumps(moves []Move, player int, start int, p int, jumped uint64) []Move {
+	x, y := BitToCartesian(p, b.Files)
+	opponent := b.Bitmaps[player^1] &^ jumped
+	// The jumping piece has left its starting square.
+	occupied := b.Occupied &^ (uint64(1) << uint(start))
+	found := false
+	for _, dy := range b.checkersDirections(player, start) {
+		for _, dx := range []int{-1, 1} {
+			i, j := x+2*dx, y+2*dy
+			if !b.onBoard(i, j) {
+				continue
+			}
+			over, to := CartesianToBit(x+dx, y+dy, b.Files), CartesianToBit(i, j, b.Files)
+			if !IsBitSet(opponent, over) || IsBitSet(occupied, to) {
+				continue
+			}
+			found = true
+			move := b.checkersMove(player, start, to, jumped|uint64(1)<<uint(over))
+			if move.Promotion == PromoteKing {
+				moves = append(moves, move)
+				continue
+			}
+			moves = b.checkersJumps(moves, player, start, to, move.Jumped)
+		}
+	}
+	if !found && jumped != 0 {
+		moves = append(moves, b.checkersMove(player, start, p, jumped))
+	}
+	return moves
+}
+
+// checkersMove returns a checkers player's move from square from to square
+// to, flagging a man that reaches the far rank for promotion.
+func (b *Bitboard) checkersMove(player int, from int, to int, jumped uint64) Move {
+	m := Move{From: from, To: to, Piece: player, Jumped: jumped}
+	_, y := BitToCartesian(to, b.Files)
+	if IsBitSet(b.Kings, from) {
+		return m
+	}
+	if (player == 0 && y == 0) || (player == 1 && y == b.Ranks-1) {
+		m.Promotion = PromoteKing
+	}
+	return m
+}
+
+// checkersDirections returns the directions along the ranks that the
+// checkers player's piece on square p may move in: forwards for a man, and
+// both ways for a king.
+func (b *Bitboard) checkersDirections(player int, p int) []int {
+	if IsBitSet(b.Kings, p) {
+		return []int{-1, 1}
+	}
+	if player == 0 {
+		return []int{-1}
+	}
+	return []int{1}
+}
+
+// onBoard reports whether Cartesian coordinates (x, y) fall on a playable
+// square of the board.
+func (b *Bitboard) onBoard(x int, y int) bool {
+	return x >= 0 && x < b.Files && y >= 0 && y < b.Ranks && IsBitSet(b.playable(), CartesianToBit(x, y, b.Files))
+}
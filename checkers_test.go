@@ -0,0 +1,146 @@
+package bitboard
+
+import (
+	"reflect"
+	"testing"
+)
+
+// emptyCheckersBoard returns a checkers board with no pieces.
+func emptyCheckersBoard() *Bitboard {
+	b := NewCheckersBoard()
+	b.Bitmaps = []uint64{0, 0}
+	b.Occupied = 0
+	return b
+}
+
+func TestCheckersMoves(t *testing.T) {
+	b := NewCheckersBoard()
+	for player := 0; player < 2; player++ {
+		moves := b.CheckersMoves(player)
+		if len(moves) != 7 {
+			t.Error("Expected 7 moves, got", len(moves))
+		}
+		for _, m := range moves {
+			if m.Piece != player || m.Jumped != 0 || m.Promotion != NoPromotion {
+				t.Error("Expected a simple move, got", m)
+			}
+		}
+	}
+	// White's men move up the board.
+	for _, m := range b.CheckersMoves(1) {
+		if m.To-m.From != 7 && m.To-m.From != 9 {
+			t.Error("Expected a forward diagonal move, got", m)
+		}
+	}
+}
+
+func TestCheckersJumps(t *testing.T) {
+	// The man on c3 must jump the man on d4 rather than move the man on a1.
+	b := emptyCheckersBoard()
+	b.PlacePieces(1, "a1", "c3")
+	b.PlacePieces(0, "d4")
+	expected := []Move{{From: 18, To: 36, Piece: 1, Jumped: 1 << 27}}
+	if moves := b.CheckersMoves(1); !reflect.DeepEqual(moves, expected) {
+		t.Error("Expected", expected, ", got", moves)
+	}
+	// A jump that can continue must: a1 jumps b2 and d4 to land on e5.
+	b = emptyCheckersBoard()
+	b.PlacePieces(1, "a1")
+	b.PlacePieces(0, "b2", "d4", "h8")
+	expected = []Move{{From: 0, To: 36, Piece: 1, Jumped: 1<<9 | 1<<27}}
+	if moves := b.CheckersMoves(1); !reflect.DeepEqual(moves, expected) {
+		t.Error("Expected", expected, ", got", moves)
+	}
+	u := b.MakeMove(expected[0])
+	if b.Occupied != 1<<36|1<<63 || b.Bitmaps[1] != 1<<36 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1<<36|1<<63), b.Occupied)
+	}
+	b.UnmakeMove(expected[0], u)
+	if b.Bitmaps[0] != 1<<9|1<<27|1<<63 || b.Bitmaps[1] != 1 {
+		t.Error("Expected jumped pieces to be restored")
+	}
+	if err := b.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+}
+
+func TestCheckersMovesKinging(t *testing.T) {
+	b := emptyCheckersBoard()
+	b.PlacePieces(1, "c7")
+	moves := b.CheckersMoves(1)
+	if len(moves) != 2 {
+		t.Fatal("Expected 2 moves, got", len(moves))
+	}
+	for _, m := range moves {
+		if m.Promotion != PromoteKing {
+			t.Error("Expected move to be flagged for kinging, got", m)
+		}
+	}
+	// A man that is crowned by a jump stops there.
+	b = emptyCheckersBoard()
+	b.PlacePieces(0, "c3")
+	b.PlacePieces(1, "b2", "d2")
+	moves = b.CheckersMoves(0)
+	if len(moves) != 2 {
+		t.Fatal("Expected 2 moves, got", len(moves))
+	}
+	for _, m := range moves {
+		if m.Promotion != PromoteKing || PopCount(m.Jumped) != 1 {
+			t.Error("Expected a single crowning jump, got", m)
+		}
+	}
+}
+
+func TestCheckersKings(t *testing.T) {
+	b := emptyCheckersBoard()
+	b.PlacePieces(1, "c7")
+	b.PlacePieces(0, "h8")
+	m := Move{From: 50, To: 57, Piece: 1, Promotion: PromoteKing}
+	u := b.MakeMove(m)
+	if b.Kings != 1<<57 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1)<<57, b.Kings)
+	}
+	// The king on b8 can now move backwards.
+	moves := b.CheckersMoves(1)
+	if len(moves) != 2 {
+		t.Fatal("Expected 2 moves, got", len(moves))
+	}
+	for _, m := range moves {
+		if m.To >= m.From || m.Promotion != NoPromotion {
+			t.Error("Expected a backward move, got", m)
+		}
+	}
+	back := b.MakeMove(moves[0])
+	if b.Kings != 1<<uint(moves[0].To) {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1)<<uint(moves[0].To), b.Kings)
+	}
+	b.UnmakeMove(moves[0], back)
+	b.UnmakeMove(m, u)
+	if b.Kings != 0 || b.SymbolAtAlgebraic("c7") != "W" {
+		t.Error("Expected the man on c7 to be uncrowned")
+	}
+	if err := b.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+}
+
+func TestCheckersKingJumps(t *testing.T) {
+	// The red king on c1 jumps backwards up the board over d2 and d4 to
+	// reach c5. Jumping the white king on d2 uncrowns it.
+	b := emptyCheckersBoard()
+	b.PlacePieces(0, "c1")
+	b.PlacePieces(1, "d4", "d2")
+	b.Kings = 1<<2 | 1<<11
+	expected := []Move{{From: 2, To: 34, Piece: 0, Jumped: 1<<11 | 1<<27}}
+	if moves := b.CheckersMoves(0); !reflect.DeepEqual(moves, expected) {
+		t.Error("Expected", expected, ", got", moves)
+	}
+	u := b.MakeMove(expected[0])
+	if b.Kings != 1<<34 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1)<<34, b.Kings)
+	}
+	b.UnmakeMove(expected[0], u)
+	if b.Kings != 1<<2|1<<11 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1<<2|1<<11), b.Kings)
+	}
+}
@@ -0,0 +1,302 @@
+package bitboard
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Chess players.
+const (
+	White = iota
+	Black
+)
+
+// Chess pieces, in the order their bitmaps appear within each player's group
+// of six bitmaps.
+const (
+	Rook = iota
+	Knight
+	Bishop
+	Queen
+	King
+	Pawn
+)
+
+// Indices of the bitmaps of a board constructed by NewChessBoard.
+const (
+	WhiteRooks = White*6 + iota
+	WhiteKnights
+	WhiteBishops
+	WhiteQueen
+	WhiteKing
+	WhitePawns
+	BlackRooks
+	BlackKnights
+	BlackBishops
+	BlackQueen
+	BlackKing
+	BlackPawns
+)
+
+// Castling availability flags.
+const (
+	WhiteKingside uint8 = 1 << iota
+	WhiteQueenside
+	BlackKingside
+	BlackQueenside
+)
+
+// castlingMasks maps each square to the castling rights lost when a piece
+// moves from or to it.
+var castlingMasks = map[int]uint8{
+	0:  WhiteQueenside,
+	4:  WhiteKingside | WhiteQueenside,
+	7:  WhiteKingside,
+	56: BlackQueenside,
+	60: BlackKingside | BlackQueenside,
+	63: BlackKingside,
+}
+
+// CanCastleKingside reports whether a chess player retains the right to
+// castle kingside.
+func (b *Bitboard) CanCastleKingside(player int) bool {
+	return b.CastlingRights&(WhiteKingside<<uint(2*player)) != 0
+}
+
+// CanCastleQueenside reports whether a chess player retains the right to
+// castle queenside.
+func (b *Bitboard) CanCastleQueenside(player int) bool {
+	return b.CastlingRights&(WhiteQueenside<<uint(2*player)) != 0
+}
+
+// NewChessBoardFromMoves constructs a chess board in the standard starting
+// position and plays the given moves in coordinate notation (e.g., "e2e4", or
+// "e7e8q" for a promotion). It returns an error identifying the first
+// malformed or illegal move.
+func NewChessBoardFromMoves(moves []string) (*Bitboard, error) {
+	b := NewChessBoard()
+	for i, s := range moves {
+		move, err := ParseUCIMove(s)
+		if err != nil {
+			return nil, fmt.Errorf("bitboard: malformed move %q at index %d", s, i)
+		}
+		legal := false
+		for _, m := range b.LegalMoves(b.SideToMove) {
+			if m.From == move.From && m.To == move.To && m.Promotion == move.Promotion {
+				b.MakeMove(m)
+				legal = true
+				break
+			}
+		}
+		if !legal {
+			return nil, fmt.Errorf("bitboard: illegal move %q at index %d", s, i)
+		}
+	}
+	return b, nil
+}
+
+// ValidateChess returns an error if the chess position could not arise in a
+// game: each player must have exactly one king, no pawns may stand on the
+// first or eighth rank, each player may have at most eight pawns and only as
+// many extra pieces as pawns they could have promoted, and the player who has
+// just moved may not be left in check.
+func (b *Bitboard) ValidateChess() error {
+	names := []string{"White", "Black"}
+	initial := [6]int{Rook: 2, Knight: 2, Bishop: 2, Queen: 1, King: 1, Pawn: 8}
+	if (b.Bitmaps[WhitePawns]|b.Bitmaps[BlackPawns])&0xff000000000000ff != 0 {
+		return errors.New("bitboard: pawns on the first or eighth rank")
+	}
+	for player, name := range names {
+		if n := PopCount(b.Bitmaps[player*6+King]); n != 1 {
+			return fmt.Errorf("bitboard: %s has %d kings", name, n)
+		}
+		pawns := PopCount(b.Bitmaps[player*6+Pawn])
+		if pawns > 8 {
+			return fmt.Errorf("bitboard: %s has %d pawns", name, pawns)
+		}
+		extra := 0
+		for piece := Rook; piece <= Queen; piece++ {
+			if n := PopCount(b.Bitmaps[player*6+piece]); n > initial[piece] {
+				extra += n - initial[piece]
+			}
+		}
+		if extra > 8-pawns {
+			return fmt.Errorf("bitboard: %s has more promoted pieces than missing pawns", name)
+		}
+	}
+	if b.InCheck(b.SideToMove ^ 1) {
+		return fmt.Errorf("bitboard: %s is in check but not to move", names[b.SideToMove^1])
+	}
+	return nil
+}
+
+// PlayerMask returns the union of a chess player's bitmaps: indices 0-5 for
+// White and 6-11 for Black.
+func (b *Bitboard) PlayerMask(player int) uint64 {
+	return Union(b.Bitmaps[player*6 : player*6+6]...)
+}
+
+// BishopColor returns the colour of the squares occupied by the pieces of
+// bitmap m: "light", "dark", "both", or "" if the bitmap is empty. Comparing
+// the colours of the players' bishops detects opposite-coloured bishops.
+func (b *Bitboard) BishopColor(m int) string {
+	dark := DarkSquares(b.Files, b.Ranks)
+	switch {
+	case b.Bitmaps[m] == 0:
+		return ""
+	case b.Bitmaps[m]&dark == 0:
+		return "light"
+	case b.Bitmaps[m]&^dark == 0:
+		return "dark"
+	}
+	return "both"
+}
+
+// InsufficientMaterial reports whether neither chess player has the material
+// to deliver checkmate: king against king, king and bishop or knight against
+// king, or king and bishop against king and bishop with both bishops on
+// squares of the same colour.
+func (b *Bitboard) InsufficientMaterial() bool {
+	counts := b.Counts()
+	for _, m := range []int{WhiteRooks, WhiteQueen, WhitePawns, BlackRooks, BlackQueen, BlackPawns} {
+		if counts[m] > 0 {
+			return false
+		}
+	}
+	white := counts[WhiteKnights] + counts[WhiteBishops]
+	black := counts[BlackKnights] + counts[BlackBishops]
+	switch {
+	case white+black <= 1:
+		return true
+	case white == 1 && black == 1 && counts[WhiteBishops] == 1 && counts[BlackBishops] == 1:
+		return b.BishopColor(WhiteBishops) == b.BishopColor(BlackBishops)
+	}
+	return false
+}
+
+// FiftyMoveDraw reports whether fifty moves by each chess player have passed
+// without a capture or a pawn move, so that either player may claim a draw.
+func (b *Bitboard) FiftyMoveDraw() bool {
+	return b.HalfmoveClock >= 100
+}
+
+// AttackersTo returns a bitmap of the chess pieces of either player that
+// attack square pos. Sliding pieces are blocked by the squares in occupied,
+// which need not match the board's occupancy (e.g., to see x-ray attacks).
+func (b *Bitboard) AttackersTo(pos int, occupied uint64) uint64 {
+	knights := b.Bitmaps[WhiteKnights] | b.Bitmaps[BlackKnights]
+	kings := b.Bitmaps[WhiteKing] | b.Bitmaps[BlackKing]
+	queens := b.Bitmaps[WhiteQueen] | b.Bitmaps[BlackQueen]
+	rooks := b.Bitmaps[WhiteRooks] | b.Bitmaps[BlackRooks] | queens
+	bishops := b.Bitmaps[WhiteBishops] | b.Bitmaps[BlackBishops] | queens
+	return KnightAttacks(pos)&knights |
+		KingAttacks(pos)&kings |
+		PawnAttacks(pos, Black)&b.Bitmaps[WhitePawns] |
+		PawnAttacks(pos, White)&b.Bitmaps[BlackPawns] |
+		RookAttacks(pos, occupied)&rooks |
+		BishopAttacks(pos, occupied)&bishops
+}
+
+// attacksFrom returns the squares attacked by the chess piece of bitmap m on
+// square p.
+func (b *Bitboard) attacksFrom(m int, p int) uint64 {
+	switch m % 6 {
+	case Rook:
+		return RookAttacks(p, b.Occupied)
+	case Knight:
+		return KnightAttacks(p)
+	case Bishop:
+		return BishopAttacks(p, b.Occupied)
+	case Queen:
+		return QueenAttacks(p, b.Occupied)
+	case King:
+		return KingAttacks(p)
+	default:
+		return PawnAttacks(p, m/6)
+	}
+}
+
+// InCheck reports whether a chess player's king is attacked by the other
+// player.
+func (b *Bitboard) InCheck(player int) bool {
+	k := LSB(b.Bitmaps[player*6+King])
+	if k == -1 {
+		return false
+	}
+	return b.IsSquareAttacked(k, player^1)
+}
+
+// DiscoveredCheckPieces returns a bitmap of a chess player's pieces that stand
+// alone between one of the player's sliding pieces and the other player's
+// king, so that moving them off the line gives check.
+func (b *Bitboard) DiscoveredCheckPieces(player int) uint64 {
+	k := LSB(b.Bitmaps[(player^1)*6+King])
+	if k == -1 {
+		return 0
+	}
+	return b.lineBlockers(k, player) & b.PlayerMask(player)
+}
+
+// PinnedPieces returns a bitmap of a chess player's pieces that stand alone
+// between the player's king and one of the other player's sliding pieces, and
+// so may only move along the line between them.
+func (b *Bitboard) PinnedPieces(player int) uint64 {
+	k := LSB(b.Bitmaps[player*6+King])
+	if k == -1 {
+		return 0
+	}
+	return b.lineBlockers(k, player^1) & b.PlayerMask(player)
+}
+
+// lineBlockers returns the pieces of either player that stand alone between
+// square k and a chess player's sliding piece that would otherwise attack it.
+func (b *Bitboard) lineBlockers(k int, player int) uint64 {
+	queens := b.Bitmaps[player*6+Queen]
+	sliders := RookAttacks(k, 0)&(b.Bitmaps[player*6+Rook]|queens) |
+		BishopAttacks(k, 0)&(b.Bitmaps[player*6+Bishop]|queens)
+	var blockers uint64
+	for ; sliders != 0; sliders &= sliders - 1 {
+		between := Between(k, LSB(sliders), 8) & b.Occupied
+		if PopCount(between) == 1 {
+			blockers |= between
+		}
+	}
+	return blockers
+}
+
+// IsCheckmate reports whether a chess player is in check and has no legal
+// moves.
+func (b *Bitboard) IsCheckmate(player int) bool {
+	return b.InCheck(player) && len(b.LegalMoves(player)) == 0
+}
+
+// IsStalemate reports whether a chess player is not in check but has no legal
+// moves.
+func (b *Bitboard) IsStalemate(player int) bool {
+	return !b.InCheck(player) && len(b.LegalMoves(player)) == 0
+}
+
+// IsSquareAttacked reports whether any of a chess player's pieces attack
+// square pos. It stops at the first attacker found, so it is cheaper than
+// AttackersTo when only a yes or no answer is needed.
+func (b *Bitboard) IsSquareAttacked(pos int, player int) bool {
+	base := player * 6
+	if KnightAttacks(pos)&b.Bitmaps[base+Knight] != 0 {
+		return true
+	}
+	if KingAttacks(pos)&b.Bitmaps[base+King] != 0 {
+		return true
+	}
+	// A pawn attacks pos if a pawn of the other colour on pos would attack it.
+	if PawnAttacks(pos, player^1)&b.Bitmaps[base+Pawn] != 0 {
+		return true
+	}
+	queens := b.Bitmaps[base+Queen]
+	if RookAttacks(pos, b.Occupied)&(b.Bitmaps[base+Rook]|queens) != 0 {
+		return true
+	}
+	if BishopAttacks(pos, b.Occupied)&(b.Bitmaps[base+Bishop]|queens) != 0 {
+		return true
+	}
+	return false
+}
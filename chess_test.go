@@ -0,0 +1,678 @@
+package bitboard
+
+import "testing"
+
+func TestNewChessBoardFromMoves(t *testing.T) {
+	b, err := NewChessBoardFromMoves([]string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5"})
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := "r1bqkbnr/pppp1ppp/2n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 3 3"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	// A capture removes the captured piece.
+	b, err = NewChessBoardFromMoves([]string{"e2e4", "d7d5", "e4d5"})
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected = "rnbqkbnr/ppp1pppp/8/3P4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+}
+
+func TestNewChessBoardFromMovesInvalid(t *testing.T) {
+	invalid := map[string][]string{
+		`bitboard: malformed move "e2" at index 1`:   {"e2e4", "e2"},
+		`bitboard: malformed move "e2z4" at index 0`: {"e2z4"},
+		`bitboard: illegal move "e2e5" at index 0`:   {"e2e5"},
+		`bitboard: illegal move "e7e5" at index 0`:   {"e7e5"},
+		`bitboard: illegal move "e1e3" at index 2`:   {"e2e4", "e7e5", "e1e3"},
+	}
+	for expected, moves := range invalid {
+		_, err := NewChessBoardFromMoves(moves)
+		if err == nil || err.Error() != expected {
+			t.Error("Expected", expected, ", got", err)
+		}
+	}
+	// Moves that leave the king in check are illegal.
+	_, err := NewChessBoardFromMoves([]string{"e2e4", "e7e5", "d1h5", "f7f6"})
+	if err == nil {
+		t.Error("Expected error for exposing the king")
+	}
+	_, err = NewChessBoardFromMoves([]string{"f2f3", "e7e5", "e1f2", "d8h4", "a2a3"})
+	if err == nil {
+		t.Error("Expected error for ignoring check")
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	b, err := ParseFEN("4k3/8/8/3p4/4P3/8/8/4K3 w")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	before := *b
+	before.Bitmaps = append([]uint64(nil), b.Bitmaps...)
+	m := Move{From: 28, To: 35, Piece: WhitePawns}
+	u := b.MakeMove(m)
+	if u.Captured != BlackPawns {
+		t.Error("Expected", BlackPawns, ", got", u.Captured)
+	}
+	if b.SymbolAt(35) != "P" || b.SymbolAt(28) != "" {
+		t.Error("Expected pawn to move from e4 to d5")
+	}
+	if b.SideToMove != Black {
+		t.Error("Expected", Black, ", got", b.SideToMove)
+	}
+	b.UnmakeMove(m, u)
+	for i := range b.Bitmaps {
+		if b.Bitmaps[i] != before.Bitmaps[i] {
+			t.Errorf("Expected bitmap %d to be %#016x, got %#016x", i, before.Bitmaps[i], b.Bitmaps[i])
+		}
+	}
+	if b.Occupied != before.Occupied || b.SideToMove != before.SideToMove {
+		t.Error("Expected UnmakeMove to restore the position")
+	}
+}
+
+func TestEnPassant(t *testing.T) {
+	b, err := ParseFEN("4k3/8/8/8/4p3/8/3P4/4K3 w")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if b.EnPassant != -1 {
+		t.Error("Expected -1, got", b.EnPassant)
+	}
+	// A double push sets the target square behind the pawn.
+	push := Move{From: 11, To: 27, Piece: WhitePawns}
+	b.MakeMove(push)
+	if b.EnPassant != 19 {
+		t.Error("Expected 19, got", b.EnPassant)
+	}
+	capture := Move{From: 28, To: 19, Piece: BlackPawns}
+	found := false
+	for _, m := range b.LegalMoves(Black) {
+		if m == capture {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected en passant capture to be generated")
+	}
+	// The capture consumes the target and removes the passing pawn.
+	u := b.MakeMove(capture)
+	if u.Captured != WhitePawns {
+		t.Error("Expected", WhitePawns, ", got", u.Captured)
+	}
+	if b.EnPassant != -1 {
+		t.Error("Expected -1, got", b.EnPassant)
+	}
+	expected := "4k3/8/8/8/8/3p4/8/4K3 w - - 0 2"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	b.UnmakeMove(capture, u)
+	expected = "4k3/8/8/8/3Pp3/8/8/4K3 b - d3 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	// The target expires if the capture is not made immediately.
+	b.MakeMove(Move{From: 60, To: 59, Piece: BlackKing})
+	if b.EnPassant != -1 {
+		t.Error("Expected -1, got", b.EnPassant)
+	}
+}
+
+func TestEnPassantNew(t *testing.T) {
+	// A chess position built from an empty board has no en passant target.
+	b, _ := New(8, 8)
+	b.Bitmaps = make([]uint64, 12)
+	b.Symbols = NewChessBoard().Symbols
+	b.PlacePieces(WhiteRooks, "a2")
+	b.PlacePieces(WhiteKing, "e1")
+	b.PlacePieces(BlackKing, "e8")
+	b.PlacePieces(BlackPawns, "b2")
+	if b.EnPassant != -1 {
+		t.Error("Expected -1, got", b.EnPassant)
+	}
+	expected := "4k3/8/8/8/8/8/Rp6/4K3 w - - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	for _, m := range b.LegalMoves(Black) {
+		if m.Piece == BlackPawns && m.To == 0 {
+			t.Error("Expected no capture onto the empty a1, got", m)
+		}
+	}
+}
+
+func TestCastlingRights(t *testing.T) {
+	b, err := ParseFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq -")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	for _, player := range []int{White, Black} {
+		if !b.CanCastleKingside(player) || !b.CanCastleQueenside(player) {
+			t.Error("Expected player", player, "to retain castling rights")
+		}
+	}
+	// Moving the h1 rook revokes only White's kingside right.
+	m := Move{From: 7, To: 15, Piece: WhiteRooks}
+	u := b.MakeMove(m)
+	if b.CanCastleKingside(White) {
+		t.Error("Expected White to lose kingside castling")
+	}
+	if !b.CanCastleQueenside(White) || !b.CanCastleKingside(Black) {
+		t.Error("Expected other castling rights to remain")
+	}
+	b.UnmakeMove(m, u)
+	if !b.CanCastleKingside(White) {
+		t.Error("Expected UnmakeMove to restore kingside castling")
+	}
+	// Capturing the a8 rook revokes Black's queenside right.
+	b.MakeMove(Move{From: 0, To: 56, Piece: WhiteRooks})
+	if b.CanCastleQueenside(Black) || b.CanCastleQueenside(White) {
+		t.Error("Expected both queenside rights to be lost")
+	}
+	// Moving the king revokes both of its rights.
+	b.MakeMove(Move{From: 60, To: 52, Piece: BlackKing})
+	if b.CanCastleKingside(Black) {
+		t.Error("Expected Black to lose kingside castling")
+	}
+	expected := "R6r/4k3/8/8/8/8/8/4K2R w K - 1 2"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+}
+
+func TestSideToMove(t *testing.T) {
+	b := NewChessBoard()
+	if b.ToMove() != White {
+		t.Error("Expected", White, ", got", b.ToMove())
+	}
+	moves := []Move{
+		{From: 12, To: 28, Piece: WhitePawns},
+		{From: 52, To: 36, Piece: BlackPawns},
+		{From: 6, To: 21, Piece: WhiteKnights},
+	}
+	for i, m := range moves {
+		b.MakeMove(m)
+		expected := (i + 1) % 2
+		if b.ToMove() != expected {
+			t.Error("Expected", expected, ", got", b.ToMove())
+		}
+	}
+	b.SetToMove(White)
+	if b.ToMove() != White {
+		t.Error("Expected", White, ", got", b.ToMove())
+	}
+	for _, fen := range []string{startFEN + " KQkq - 0 1", "4k3/8/8/8/8/8/8/4K3 b - - 0 1"} {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if result := b.FEN(); result != fen {
+			t.Error("Expected", fen, ", got", result)
+		}
+	}
+	b, _ = ParseFEN("4k3/8/8/8/8/8/8/4K3 b - - 0 1")
+	if b.ToMove() != Black {
+		t.Error("Expected", Black, ", got", b.ToMove())
+	}
+}
+
+func TestValidateChess(t *testing.T) {
+	if err := NewChessBoard().ValidateChess(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	valid := []string{
+		"4k3/8/8/8/8/8/8/4K3 w",
+		// Two queens after a promotion.
+		"4k3/8/8/8/8/8/PPPPPPP1/QQ2K3 w",
+	}
+	for _, fen := range valid {
+		b, _ := ParseFEN(fen)
+		if err := b.ValidateChess(); err != nil {
+			t.Error("Expected no error for", fen, ", got", err)
+		}
+	}
+	invalid := []string{
+		"4k3/8/8/8/8/8/8/4KK2 w",          // two white kings
+		"8/8/8/8/8/8/8/4K3 w",             // no black king
+		"4k3/8/8/8/8/8/8/P3K3 w",          // pawn on the first rank
+		"p3k3/8/8/8/8/8/8/4K3 w",          // pawn on the eighth rank
+		"4k3/8/8/8/8/P7/PPPPPPPP/4K3 w",   // nine pawns
+		"4k3/8/8/8/8/8/PPPPPPPP/QQ2K3 w",  // a second queen without a promotion
+		"4k3/8/8/8/8/8/PPPPPPP1/NNNNK3 w", // four knights but only one promotion
+		"4k3/8/8/8/8/8/8/4K2r b",          // White in check with Black to move
+	}
+	for _, fen := range invalid {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal("Expected no error parsing", fen, ", got", err)
+		}
+		if err := b.ValidateChess(); err == nil {
+			t.Error("Expected error for", fen)
+		}
+	}
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	var tests = []struct {
+		fen      string
+		expected bool
+	}{
+		{"4k3/8/8/8/8/8/8/4K3 w", true},
+		{"4k3/8/8/8/8/8/8/4KN2 w", true},
+		{"4k3/8/8/8/8/8/8/4KB2 w", true},
+		{"4kb2/8/8/8/8/8/8/4K3 w", true},
+		// Bishops on f1 and c8 both stand on light squares.
+		{"2b1k3/8/8/8/8/8/8/4KB2 w", true},
+		// Bishops on f1 and f8 stand on squares of opposite colours.
+		{"4kb2/8/8/8/8/8/8/4KB2 w", false},
+		{"4kn2/8/8/8/8/8/8/4KB2 w", false},
+		{"4k3/8/8/8/8/8/8/3NKN2 w", false},
+		{"4k3/8/8/8/8/8/4P3/4K3 w", false},
+		{"4k3/8/8/8/8/8/8/R3K3 w", false},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w", false},
+	}
+	for _, test := range tests {
+		b, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if result := b.InsufficientMaterial(); result != test.expected {
+			t.Error("Expected", test.expected, "for", test.fen, ", got", result)
+		}
+	}
+}
+
+func TestHalfmoveClock(t *testing.T) {
+	b, _ := ParseFEN("4k3/8/8/3p4/8/8/8/R3K1N1 w - - 7 20")
+	if b.HalfmoveClock != 7 || b.FullmoveNumber != 20 {
+		t.Error("Expected 7 and 20, got", b.HalfmoveClock, b.FullmoveNumber)
+	}
+	moves := []struct {
+		m        Move
+		expected int
+	}{
+		{Move{From: 6, To: 21, Piece: WhiteKnights}, 8},
+		{Move{From: 60, To: 59, Piece: BlackKing}, 9},
+		// A capture resets the clock.
+		{Move{From: 21, To: 35, Piece: WhiteKnights}, 0},
+		{Move{From: 59, To: 60, Piece: BlackKing}, 1},
+		{Move{From: 0, To: 8, Piece: WhiteRooks}, 2},
+	}
+	var undos []Undo
+	for _, test := range moves {
+		undos = append(undos, b.MakeMove(test.m))
+		if b.HalfmoveClock != test.expected {
+			t.Error("Expected", test.expected, ", got", b.HalfmoveClock)
+		}
+	}
+	expected := "4k3/8/8/3N4/8/8/R7/4K3 b - - 2 22"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	for i := len(moves) - 1; i >= 0; i-- {
+		b.UnmakeMove(moves[i].m, undos[i])
+	}
+	if b.HalfmoveClock != 7 || b.FullmoveNumber != 20 {
+		t.Error("Expected 7 and 20, got", b.HalfmoveClock, b.FullmoveNumber)
+	}
+	// A pawn move resets the clock too.
+	b.SideToMove = Black
+	b.MakeMove(Move{From: 35, To: 27, Piece: BlackPawns})
+	if b.HalfmoveClock != 0 {
+		t.Error("Expected 0, got", b.HalfmoveClock)
+	}
+	for _, fen := range []string{"4k3/8/8/8/8/8/8/4K3 w - - -1 1", "4k3/8/8/8/8/8/8/4K3 w - - 0 0", "4k3/8/8/8/8/8/8/4K3 w - - x 1"} {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Error("Expected error for", fen)
+		}
+	}
+}
+
+func TestFiftyMoveDraw(t *testing.T) {
+	b, _ := ParseFEN("4k3/8/8/8/8/8/8/R3K3 w - - 98 80")
+	moves := []Move{{From: 0, To: 8, Piece: WhiteRooks}, {From: 60, To: 59, Piece: BlackKing}}
+	b.MakeMove(moves[0])
+	if b.FiftyMoveDraw() {
+		t.Error("Expected no draw after 99 halfmoves")
+	}
+	b.MakeMove(moves[1])
+	if !b.FiftyMoveDraw() {
+		t.Error("Expected a draw after 100 halfmoves")
+	}
+	if result := NewGame(b).Result(); result != "1/2-1/2" {
+		t.Error("Expected 1/2-1/2, got", result)
+	}
+}
+
+func TestPlayerMask(t *testing.T) {
+	b := NewChessBoard()
+	if result := b.PlayerMask(White); result != 0x000000000000ffff {
+		t.Errorf("Expected %#016x, got %#016x", 0x000000000000ffff, result)
+	}
+	if result := b.PlayerMask(Black); result != 0xffff000000000000 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0xffff000000000000), result)
+	}
+	if b.PlayerMask(White)|b.PlayerMask(Black) != b.Occupied {
+		t.Error("Expected players to cover the occupied squares")
+	}
+}
+
+func TestBishopColor(t *testing.T) {
+	b := NewChessBoard()
+	if result := b.BishopColor(WhiteBishops); result != "both" {
+		t.Error("Expected both, got", result)
+	}
+	// Opposite-coloured bishops: c1 is dark and c8 is light.
+	b, _ = ParseFEN("2b1k3/8/8/8/8/8/8/2B1K3 w")
+	if result := b.BishopColor(WhiteBishops); result != "dark" {
+		t.Error("Expected dark, got", result)
+	}
+	if result := b.BishopColor(BlackBishops); result != "light" {
+		t.Error("Expected light, got", result)
+	}
+	if result := b.BishopColor(WhiteKnights); result != "" {
+		t.Error("Expected empty string, got", result)
+	}
+}
+
+func TestInCheck(t *testing.T) {
+	tests := map[string]bool{
+		"4k3/8/8/8/8/8/8/4K2r w":    true, // rook along the first rank
+		"4k3/8/8/8/8/3n4/8/4K3 w":   true, // knight on d3
+		"4k3/8/8/8/8/8/3p4/4K3 w":   true, // pawn on d2
+		"4k3/8/8/8/8/8/8/r3K3 b":    true, // side to move does not matter
+		"4k3/4r3/8/8/8/8/4P3/4K3 w": false,
+		"4k3/8/8/8/8/8/8/4K3 w":     false,
+	}
+	for fen, expected := range tests {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if result := b.InCheck(White); result != expected {
+			t.Error("Expected", expected, "for", fen, ", got", result)
+		}
+	}
+	b, _ := ParseFEN("4k3/8/8/8/8/8/8/4K2R b")
+	if b.InCheck(White) || b.InCheck(Black) {
+		t.Error("Expected neither king to be in check")
+	}
+}
+
+func TestCastling(t *testing.T) {
+	kingside := Move{From: 4, To: 6, Piece: WhiteKing}
+	queenside := Move{From: 4, To: 2, Piece: WhiteKing}
+	hasMove := func(b *Bitboard, move Move) bool {
+		for _, m := range b.LegalMoves(White) {
+			if m == move {
+				return true
+			}
+		}
+		return false
+	}
+	b, _ := ParseFEN("4k3/8/8/8/8/8/8/R3K2R w KQ")
+	if !hasMove(b, kingside) || !hasMove(b, queenside) {
+		t.Error("Expected both castling moves")
+	}
+	u := b.MakeMove(kingside)
+	expected := "4k3/8/8/8/8/8/8/R4RK1 b - - 1 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	b.UnmakeMove(kingside, u)
+	expected = "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	// Castling requires the right to castle.
+	b, _ = ParseFEN("4k3/8/8/8/8/8/8/R3K2R w Q")
+	if hasMove(b, kingside) || !hasMove(b, queenside) {
+		t.Error("Expected only queenside castling")
+	}
+	// A knight on g1 blocks kingside castling.
+	b, _ = ParseFEN("4k3/8/8/8/8/8/8/R3K1NR w KQ")
+	if hasMove(b, kingside) {
+		t.Error("Expected blocked castling to be rejected")
+	}
+	// The king may not castle out of, through, or into check.
+	for _, fen := range []string{
+		"4k3/8/8/8/8/8/8/R3K1rR w KQ", // e1 is attacked
+		"4k3/8/b7/8/8/8/8/R3K2R w KQ", // f1 is attacked
+		"4k3/8/8/8/8/8/7p/R3K2R w KQ", // g1 is attacked
+	} {
+		b, _ = ParseFEN(fen)
+		if hasMove(b, kingside) {
+			t.Error("Expected castling to be rejected for", fen)
+		}
+	}
+	// The rook, but not the king, may cross an attacked square.
+	b, _ = ParseFEN("1r2k3/8/8/8/8/8/8/R3K2R w KQ")
+	if !hasMove(b, queenside) {
+		t.Error("Expected queenside castling with b1 attacked")
+	}
+	b, _ = ParseFEN("r3k2r/8/8/8/8/8/8/4K3 b kq")
+	b.MakeMove(Move{From: 60, To: 58, Piece: BlackKing})
+	expected = "2kr3r/8/8/8/8/8/8/4K3 w - - 1 2"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+}
+
+func TestIsSquareAttacked(t *testing.T) {
+	// The bishop on a6 covers f1, which the king crosses to castle kingside.
+	b, _ := ParseFEN("4k3/8/b7/8/8/8/8/R3K2R w KQ")
+	expected := map[string]bool{
+		"f1": true,
+		"g1": false,
+		"d1": false,
+		"c1": false,
+	}
+	for square, attacked := range expected {
+		if result := b.IsSquareAttacked(b.AlgebraicToBit(square), Black); result != attacked {
+			t.Error("Expected", attacked, "for", square, ", got", result)
+		}
+	}
+	// A knight on e3 covers the queenside squares c2 and d1.
+	b, _ = ParseFEN("4k3/8/8/8/8/4n3/8/R3K2R w KQ")
+	if !b.IsSquareAttacked(b.AlgebraicToBit("d1"), Black) {
+		t.Error("Expected d1 to be attacked")
+	}
+	if b.IsSquareAttacked(b.AlgebraicToBit("c1"), Black) {
+		t.Error("Expected c1 not to be attacked")
+	}
+	// White's own king defends f1.
+	if !b.IsSquareAttacked(b.AlgebraicToBit("f1"), White) {
+		t.Error("Expected f1 to be attacked by White")
+	}
+}
+
+func TestDiscoveredCheckPieces(t *testing.T) {
+	if result := NewChessBoard().DiscoveredCheckPieces(White); result != 0 {
+		t.Errorf("Expected %#016x, got %#016x", 0, result)
+	}
+	// The knight on e4 masks the rook on e1 from the king on e8. The bishop
+	// on a4 is lined up too, but the pawn on d7 in its way is Black's own.
+	b, _ := ParseFEN("4k3/3p4/8/8/B3N3/8/8/K3R3 w")
+	expected := uint64(1) << 28
+	if result := b.DiscoveredCheckPieces(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	// Two pieces in the way hide the rook entirely.
+	b.PlacePieceAlgebraic(WhitePawns, "e3")
+	if result := b.DiscoveredCheckPieces(White); result != 0 {
+		t.Errorf("Expected %#016x, got %#016x", 0, result)
+	}
+	// A queen discovers along diagonals as well.
+	b, _ = ParseFEN("7k/8/8/8/3B4/8/1Q6/K7 w")
+	expected = uint64(1) << 27
+	if result := b.DiscoveredCheckPieces(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestPinnedPieces(t *testing.T) {
+	if result := NewChessBoard().PinnedPieces(White); result != 0 {
+		t.Errorf("Expected %#016x, got %#016x", 0, result)
+	}
+	// The knight on c3 is pinned by the bishop on a5, and the rook on e4 by
+	// the rook on e8.
+	b, _ := ParseFEN("4r2k/8/8/b7/4R3/2N5/5P2/4K3 w")
+	expected := uint64(1<<18 | 1<<28)
+	if result := b.PinnedPieces(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	// The pinned knight cannot move, and the pinned rook may only move along
+	// the e-file.
+	for _, m := range b.LegalMoves(White) {
+		if m.From == 18 || (m.From == 28 && m.To%8 != 4) {
+			t.Error("Expected pinned pieces to stay on the line, got", m)
+		}
+	}
+	// A piece of the other player in between does not pin.
+	b.PlacePieceAlgebraic(BlackPawns, "b4")
+	expected = uint64(1) << 28
+	if result := b.PinnedPieces(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestIsCheckmate(t *testing.T) {
+	// Back-rank mate: the black king is hemmed in by its own pawns.
+	b, _ := ParseFEN("R5k1/5ppp/8/8/8/8/8/6K1 b")
+	if !b.IsCheckmate(Black) {
+		t.Error("Expected checkmate")
+	}
+	if b.IsStalemate(Black) {
+		t.Error("Expected no stalemate")
+	}
+	// Black can block the check on the back rank with the rook.
+	b, _ = ParseFEN("R5k1/5ppp/8/8/8/8/8/2r3K1 b")
+	if b.IsCheckmate(Black) {
+		t.Error("Expected no checkmate")
+	}
+	if NewChessBoard().IsCheckmate(White) {
+		t.Error("Expected no checkmate")
+	}
+}
+
+func TestIsStalemate(t *testing.T) {
+	b, _ := ParseFEN("7k/5Q2/6K1/8/8/8/8/8 b")
+	if !b.IsStalemate(Black) {
+		t.Error("Expected stalemate")
+	}
+	if b.IsCheckmate(Black) {
+		t.Error("Expected no checkmate")
+	}
+	if NewChessBoard().IsStalemate(White) {
+		t.Error("Expected no stalemate")
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	if result := len(NewChessBoard().LegalMoves(White)); result != 20 {
+		t.Error("Expected 20, got", result)
+	}
+	// The knight on e2 is pinned to the king by the rook on e8.
+	b, _ := ParseFEN("4r1k1/8/8/8/8/8/4N3/4K3 w")
+	for _, m := range b.LegalMoves(White) {
+		if m.Piece == WhiteKnights {
+			t.Error("Expected pinned knight not to move, got", m)
+		}
+	}
+	// The king is in check from the rook on e8 and must step off the file.
+	b, _ = ParseFEN("4r1k1/8/8/8/8/8/8/4K3 w")
+	moves := b.LegalMoves(White)
+	if len(moves) != 4 {
+		t.Error("Expected 4, got", len(moves))
+	}
+	for _, m := range moves {
+		if m.Piece != WhiteKing || m.To%8 == 4 {
+			t.Error("Expected king to leave the e-file, got", m)
+		}
+	}
+	// Interposing a piece also resolves the check.
+	b, _ = ParseFEN("4r1k1/8/8/8/8/8/1B6/4K3 w")
+	block := Move{From: 9, To: 36, Piece: WhiteBishops}
+	found := false
+	for _, m := range b.LegalMoves(White) {
+		if m == block {
+			found = true
+		} else if m.Piece == WhiteBishops {
+			t.Error("Expected bishop move to leave king in check, got", m)
+		}
+	}
+	if !found {
+		t.Error("Expected", block, "to be legal")
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	b, _ := ParseFEN("1n2k3/P7/8/8/8/8/8/4K3 w")
+	var promotions []Move
+	for _, m := range b.LegalMoves(White) {
+		if m.Piece == WhitePawns {
+			promotions = append(promotions, m)
+		}
+	}
+	// Four choices each for a8 and the capture on b8.
+	if len(promotions) != 8 {
+		t.Error("Expected 8, got", len(promotions))
+	}
+	for _, m := range promotions {
+		if m.Promotion == NoPromotion {
+			t.Error("Expected promotion, got", m)
+		}
+	}
+	m := Move{From: 48, To: 56, Piece: WhitePawns, Promotion: PromoteQueen}
+	u := b.MakeMove(m)
+	expected := "Qn2k3/8/8/8/8/8/8/4K3 b - - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	b.UnmakeMove(m, u)
+	m = Move{From: 48, To: 57, Piece: WhitePawns, Promotion: PromoteKnight}
+	u = b.MakeMove(m)
+	expected = "1N2k3/8/8/8/8/8/8/4K3 b - - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	b.UnmakeMove(m, u)
+	expected = "1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	if err := b.CheckInvariants(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	// Black pawns promote on the first rank.
+	b, _ = ParseFEN("4k3/8/8/8/8/8/p7/4K3 b")
+	b.MakeMove(Move{From: 8, To: 0, Piece: BlackPawns, Promotion: PromoteRook})
+	if b.SymbolAt(0) != "r" {
+		t.Error("Expected r, got", b.SymbolAt(0))
+	}
+}
+
+func TestNewChessBoardFromMovesPromotion(t *testing.T) {
+	moves := []string{"h2h4", "g7g5", "h4g5", "h7h6", "g5h6", "g8f6", "h6h7", "f6g8", "h7g8n"}
+	b, err := NewChessBoardFromMoves(moves)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if b.SymbolAtAlgebraic("g8") != "N" {
+		t.Error("Expected N, got", b.SymbolAtAlgebraic("g8"))
+	}
+	moves[len(moves)-1] = "h7g8"
+	if _, err := NewChessBoardFromMoves(moves); err == nil {
+		t.Error("Expected error for missing promotion")
+	}
+	moves[len(moves)-1] = "h7g8k"
+	if _, err := NewChessBoardFromMoves(moves); err == nil {
+		t.Error("Expected error for invalid promotion")
+	}
+}
@@ -0,0 +1,142 @@
+package bitboard
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// Binary encoding of a Bitboard, in order:
+//
+//   - a version byte
+//   - one byte each for the ranks, files, number of bitmaps, side to move,
+//     en passant square (as a signed byte), and castling availability
+//   - the halfmove clock and fullmove number as big-endian uint16s
+//   - the playable squares and kings as big-endian uint64s
+//   - each bitmap as a big-endian uint64, followed by the length of its symbol
+//     in bytes and the symbol itself
+//   - a big-endian CRC-32 (IEEE) checksum of everything before it
+const encodingVersion = 3
+
+var errInvalidEncoding = errors.New("bitboard: invalid encoding")
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (b *Bitboard) MarshalBinary() ([]byte, error) {
+	if len(b.Bitmaps) > 255 || len(b.Symbols) < len(b.Bitmaps) {
+		return nil, errors.New("bitboard: cannot encode board")
+	}
+	if b.HalfmoveClock < 0 || b.HalfmoveClock > 0xffff || b.FullmoveNumber < 0 || b.FullmoveNumber > 0xffff {
+		return nil, errors.New("bitboard: cannot encode board")
+	}
+	data := []byte{
+		encodingVersion,
+		byte(b.Ranks),
+		byte(b.Files),
+		byte(len(b.Bitmaps)),
+		byte(b.SideToMove),
+		byte(int8(b.EnPassant)),
+		b.CastlingRights,
+	}
+	data = appendUint16(data, uint16(b.HalfmoveClock))
+	data = appendUint16(data, uint16(b.FullmoveNumber))
+	data = appendUint64(data, b.Playable)
+	data = appendUint64(data, b.Kings)
+	for i, m := range b.Bitmaps {
+		if len(b.Symbols[i]) > 255 {
+			return nil, errors.New("bitboard: cannot encode board")
+		}
+		data = appendUint64(data, m)
+		data = append(data, byte(len(b.Symbols[i])))
+		data = append(data, b.Symbols[i]...)
+	}
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(data))
+	return append(data, sum[:]...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (b *Bitboard) UnmarshalBinary(data []byte) error {
+	if len(data) < 31 {
+		return errInvalidEncoding
+	}
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return errors.New("bitboard: encoding checksum mismatch")
+	}
+	if body[0] != encodingVersion {
+		return errors.New("bitboard: unsupported encoding version")
+	}
+	c := Bitboard{
+		Ranks:          int(body[1]),
+		Files:          int(body[2]),
+		SideToMove:     int(body[4]),
+		EnPassant:      int(int8(body[5])),
+		CastlingRights: body[6],
+		HalfmoveClock:  int(binary.BigEndian.Uint16(body[7:9])),
+		FullmoveNumber: int(binary.BigEndian.Uint16(body[9:11])),
+		Playable:       binary.BigEndian.Uint64(body[11:19]),
+		Kings:          binary.BigEndian.Uint64(body[19:27]),
+	}
+	if c.Ranks*c.Files > MaxSquares {
+		return errInvalidEncoding
+	}
+	n := int(body[3])
+	body = body[27:]
+	for i := 0; i < n; i++ {
+		if len(body) < 9 || len(body) < 9+int(body[8]) {
+			return errInvalidEncoding
+		}
+		end := 9 + int(body[8])
+		c.Bitmaps = append(c.Bitmaps, binary.BigEndian.Uint64(body[:8]))
+		c.Symbols = append(c.Symbols, string(body[9:end]))
+		body = body[end:]
+	}
+	if len(body) != 0 {
+		return errInvalidEncoding
+	}
+	c.RecomputeOccupied()
+	if err := c.CheckInvariants(); err != nil {
+		return err
+	}
+	*b = c
+	return nil
+}
+
+// Encode returns a compact, URL-safe token representing the board, suitable
+// for sharing positions. Decode reverses it. Encode returns an empty string if
+// the board has more than 255 bitmaps or a symbol longer than 255 bytes.
+func (b *Bitboard) Encode() string {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode constructs a Bitboard from a token returned by Encode.
+func Decode(s string) (*Bitboard, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidEncoding
+	}
+	b := &Bitboard{}
+	if err := b.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// appendUint16 appends i to data in big-endian byte order.
+func appendUint16(data []byte, i uint16) []byte {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], i)
+	return append(data, buf[:]...)
+}
+
+// appendUint64 appends i to data in big-endian byte order.
+func appendUint64(data []byte, i uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], i)
+	return append(data, buf[:]...)
+}
@@ -0,0 +1,59 @@
+package bitboard
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	boards := []*Bitboard{
+		NewChessBoard(),
+		NewConnectFourBoard(),
+		NewTicTacToeBoard(),
+	}
+	b, _ := NewChessBoardFromMoves([]string{"e2e4", "c7c5"})
+	c := NewCheckersBoard()
+	c.Kings = 1 << 2
+	boards = append(boards, b, c)
+	for _, b := range boards {
+		s := b.Encode()
+		result, err := Decode(s)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if !reflect.DeepEqual(result, b) {
+			t.Error("Expected", b, ", got", result)
+		}
+		if s != result.Encode() {
+			t.Error("Expected", s, ", got", result.Encode())
+		}
+	}
+	if result := b.Encode(); strings.ContainsAny(result, "+/=") {
+		t.Error("Expected URL-safe token, got", result)
+	}
+}
+
+func TestDecodeTampered(t *testing.T) {
+	s := NewChessBoard().Encode()
+	tampered := []string{
+		"",
+		s[:len(s)-1],
+		s[:10],
+		s + "A",
+		"!" + s[1:],
+	}
+	// Change each character in turn.
+	for i := range s {
+		c := byte('A')
+		if s[i] == 'A' {
+			c = 'B'
+		}
+		tampered = append(tampered, s[:i]+string(c)+s[i+1:])
+	}
+	for _, token := range tampered {
+		if b, err := Decode(token); err == nil {
+			t.Error("Expected error for", token, ", got", b)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package bitboard
+
+// Evaluation helpers for chess. These assume the bitmap ordering used by
+// NewChessBoard.
+
+// pieceValues holds the value of each chess piece in centipawns, indexed by
+// piece. The king is not counted.
+var pieceValues = [6]int{
+	Rook:   500,
+	Knight: 320,
+	Bishop: 330,
+	Queen:  900,
+	King:   0,
+	Pawn:   100,
+}
+
+// MaterialBalance returns White's material minus Black's in centipawns. It
+// uses the piece counts cached by Counts.
+func (b *Bitboard) MaterialBalance() int {
+	balance := 0
+	counts := b.Counts()
+	for piece, value := range pieceValues {
+		balance += value * counts[White*6+piece]
+		balance -= value * counts[Black*6+piece]
+	}
+	return balance
+}
+
+// PSQTScore returns White's positional advantage from piece-square tables.
+// For every piece of bitmap m on square p, it adds the table entry
+// tables[m][p] for White's bitmaps and subtracts it for Black's. If only
+// White's six tables are given, Black's pieces are scored with White's tables
+// mirrored vertically. Bitmaps without a table, or with a nil table, score
+// nothing.
+func (b *Bitboard) PSQTScore(tables [][]int) int {
+	score := 0
+	mirror := len(tables) == 6
+	for m, bitmap := range b.Bitmaps {
+		table, sign, flip := []int(nil), 1, 0
+		if m >= 6 && m < 12 {
+			sign = -1
+		}
+		switch {
+		case m < len(tables):
+			table = tables[m]
+		case mirror && m < 12:
+			table, flip = tables[m-6], 56
+		}
+		if table == nil {
+			continue
+		}
+		for ; bitmap != 0; bitmap &= bitmap - 1 {
+			score += sign * table[LSB(bitmap)^flip]
+		}
+	}
+	return score
+}
+
+// ControlMap returns, for each square, the number of a chess player's pieces
+// that attack it.
+func (b *Bitboard) ControlMap(player int) [64]int {
+	var control [64]int
+	for m := player * 6; m < player*6+6; m++ {
+		for pieces := b.Bitmaps[m]; pieces != 0; pieces &= pieces - 1 {
+			for a := b.attacksFrom(m, LSB(pieces)); a != 0; a &= a - 1 {
+				control[LSB(a)]++
+			}
+		}
+	}
+	return control
+}
+
+// SpaceAdvantage returns the number of squares attacked only by White minus
+// the number attacked only by Black.
+func (b *Bitboard) SpaceAdvantage() int {
+	white, black := b.ControlMap(White), b.ControlMap(Black)
+	space := 0
+	for p := range white {
+		switch {
+		case white[p] > 0 && black[p] == 0:
+			space++
+		case black[p] > 0 && white[p] == 0:
+			space--
+		}
+	}
+	return space
+}
+
+// Mobility returns the total number of squares a chess player's pieces can
+// move to, ignoring whether the moves would leave the king in check.
+func (b *Bitboard) Mobility(player int) int {
+	mobility := 0
+	for m := player * 6; m < player*6+6; m++ {
+		for pieces := b.Bitmaps[m]; pieces != 0; pieces &= pieces - 1 {
+			mobility += PopCount(b.destinations(m, LSB(pieces)))
+		}
+	}
+	return mobility
+}
+
+// MobilityDelta returns the change in a chess player's mobility if move m were
+// played. The board is left as it was.
+func (b *Bitboard) MobilityDelta(m Move, player int) int {
+	before := b.Mobility(player)
+	u := b.MakeMove(m)
+	after := b.Mobility(player)
+	b.UnmakeMove(m, u)
+	return after - before
+}
+
+// PassedPawns returns a bitmap of a chess player's pawns that have no enemy
+// pawns ahead of them on the same or adjacent files.
+func (b *Bitboard) PassedPawns(player int) uint64 {
+	enemy := b.Bitmaps[(player^1)*6+Pawn]
+	// The squares each enemy pawn guards on its way to promotion.
+	var span uint64
+	if player == White {
+		span = SouthFill(enemy >> 8)
+	} else {
+		span = NorthFill(enemy << 8)
+	}
+	span |= (span<<1)&^FileMask[0] | (span>>1)&^FileMask[7]
+	return b.Bitmaps[player*6+Pawn] &^ span
+}
+
+// DoubledPawns returns a bitmap of a chess player's pawns that share a file
+// with another of the player's pawns.
+func (b *Bitboard) DoubledPawns(player int) uint64 {
+	pawns := b.Bitmaps[player*6+Pawn]
+	return pawns & (NorthFill(pawns<<8) | SouthFill(pawns>>8))
+}
+
+// IsolatedPawns returns a bitmap of a chess player's pawns that have none of
+// the player's pawns on the adjacent files.
+func (b *Bitboard) IsolatedPawns(player int) uint64 {
+	pawns := b.Bitmaps[player*6+Pawn]
+	files := FileFill(pawns)
+	neighbours := (files<<1)&^FileMask[0] | (files>>1)&^FileMask[7]
+	return pawns &^ neighbours
+}
+
+// OpenFiles returns a bitmap of the whole files that hold no pawns of either
+// chess player. AND it with a rook bitmap to find rooks on open files.
+func (b *Bitboard) OpenFiles() uint64 {
+	return ^FileFill(b.Bitmaps[WhitePawns] | b.Bitmaps[BlackPawns])
+}
+
+// HalfOpenFiles returns a bitmap of the whole files that hold none of a chess
+// player's pawns. This includes open files.
+func (b *Bitboard) HalfOpenFiles(player int) uint64 {
+	return ^FileFill(b.Bitmaps[player*6+Pawn])
+}
+
+// HangingPieces returns a bitmap of a chess player's pieces that the other
+// player attacks and that none of the player's own pieces defend. The king is
+// never counted.
+func (b *Bitboard) HangingPieces(player int) uint64 {
+	own, enemy := b.PlayerMask(player), b.PlayerMask(player^1)
+	var hanging uint64
+	for pieces := own &^ b.Bitmaps[player*6+King]; pieces != 0; pieces &= pieces - 1 {
+		p := LSB(pieces)
+		attackers := b.AttackersTo(p, b.Occupied)
+		if attackers&enemy != 0 && attackers&own == 0 {
+			SetBit(&hanging, p)
+		}
+	}
+	return hanging
+}
+
+// Forks returns a chess player's legal moves after which the moved piece
+// attacks two or more of the other player's pieces.
+func (b *Bitboard) Forks(player int) []Move {
+	var forks []Move
+	for _, m := range b.LegalMoves(player) {
+		u := b.MakeMove(m)
+		piece := m.Piece
+		if m.Promotion != NoPromotion {
+			piece = promotedPiece(m)
+		}
+		if PopCount(b.attacksFrom(piece, m.To)&b.PlayerMask(player^1)) >= 2 {
+			forks = append(forks, m)
+		}
+		b.UnmakeMove(m, u)
+	}
+	return forks
+}
+
+// seeOrder lists chess pieces from least to most valuable, the order in which
+// SEE commits attackers to an exchange.
+var seeOrder = [6]int{Pawn, Knight, Bishop, Rook, Queen, King}
+
+// seeValue returns the value of the piece of bitmap m for SEE, or 0 if m is
+// -1. The king is worth more than everything else, so a king never profits
+// from recapturing on a defended square.
+func seeValue(m int) int {
+	switch {
+	case m == -1:
+		return 0
+	case m%6 == King:
+		return 20000
+	}
+	return pieceValues[m%6]
+}
+
+// SEE returns the static exchange evaluation of the piece on square from
+// capturing on square to: the material the moving player stands to gain, in
+// centipawns, if both players keep recapturing on to with their least valuable
+// attacker for as long as it pays. Attacks revealed behind the capturing
+// pieces (x-rays) are included. It returns 0 if from is empty.
+func (b *Bitboard) SEE(from int, to int) int {
+	m := b.GetBitmapIndex(from)
+	if m == -1 {
+		return 0
+	}
+	gain := []int{seeValue(b.GetBitmapIndex(to))}
+	occupied := b.Occupied
+	player := m / 6
+	for {
+		// Speculatively capture the piece that just moved to the square.
+		gain = append(gain, seeValue(m)-gain[len(gain)-1])
+		ClearBit(&occupied, from)
+		player ^= 1
+		attackers := b.AttackersTo(to, occupied) & occupied
+		from = -1
+		for _, piece := range seeOrder {
+			if a := attackers & b.Bitmaps[player*6+piece]; a != 0 {
+				m, from = player*6+piece, LSB(a)
+				break
+			}
+		}
+		if from == -1 {
+			break
+		}
+	}
+	// The last capture was never made. Either player may stop recapturing
+	// when it no longer pays.
+	for d := len(gain) - 2; d > 0; d-- {
+		if gain[d] > -gain[d-1] {
+			gain[d-1] = -gain[d]
+		}
+	}
+	return gain[0]
+}
@@ -0,0 +1,308 @@
+package bitboard
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaterialBalance(t *testing.T) {
+	if result := NewChessBoard().MaterialBalance(); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+	b, _ := ParseFEN("1nbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w")
+	if result := b.MaterialBalance(); result != 500 {
+		t.Error("Expected 500, got", result)
+	}
+	b, _ = ParseFEN("4k3/8/8/8/8/8/8/3QK3 w")
+	b.SetToMove(Black)
+	if result := b.MaterialBalance(); result != 900 {
+		t.Error("Expected 900, got", result)
+	}
+	b, _ = ParseFEN("4k3/pppp4/8/8/8/8/8/4K1N1 w")
+	if result := b.MaterialBalance(); result != -80 {
+		t.Error("Expected -80, got", result)
+	}
+}
+
+func TestPSQTScore(t *testing.T) {
+	// Reward knights for standing away from the edges.
+	centre := make([]int, 64)
+	for p := range centre {
+		// d is twice the distance from the centre, and 7 on an edge.
+		x, y := BitToCartesian(p, 8)
+		d := abs(2*x - 7)
+		if e := abs(2*y - 7); e > d {
+			d = e
+		}
+		centre[p] = 10 * (7 - d) / 2
+	}
+	tables := make([][]int, 6)
+	tables[Knight] = centre
+	b := NewChessBoard()
+	if result := b.PSQTScore(tables); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+	// A knight on f3 is worth more than one on g1.
+	b.MovePieceAlgebraic(WhiteKnights, "g1", "f3")
+	if result := b.PSQTScore(tables); result != 20 {
+		t.Error("Expected 20, got", result)
+	}
+	// Black's mirrored reply cancels it out.
+	b.MovePieceAlgebraic(BlackKnights, "g8", "f6")
+	if result := b.PSQTScore(tables); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+	// With a table for every bitmap, Black's entries are used as given but
+	// still count against White.
+	tables = make([][]int, 12)
+	tables[WhiteKnights], tables[BlackKnights] = centre, centre
+	if result := b.PSQTScore(tables); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+	tables[BlackKnights] = make([]int, 64)
+	tables[BlackKnights][b.AlgebraicToBit("f6")] = 50
+	if result := b.PSQTScore(tables); result != -30 {
+		t.Error("Expected -30, got", result)
+	}
+}
+
+func TestControlMap(t *testing.T) {
+	b := NewChessBoard()
+	control := b.ControlMap(White)
+	expected := map[string]int{"c3": 3, "d3": 2, "e3": 2, "f3": 3, "d2": 4, "e4": 0}
+	for p, n := range expected {
+		if result := control[b.AlgebraicToBit(p)]; result != n {
+			t.Error("Expected", n, "attackers of", p, ", got", result)
+		}
+	}
+	// After 1. e4 e5 2. Nf3 Nc6 3. Nc3 Nf6 4. d3 d6, d5 is among White's most
+	// contested squares in Black's half, attacked by the e4 pawn and c3 knight.
+	b, _ = NewChessBoardFromMoves([]string{"e2e4", "e7e5", "g1f3", "b8c6", "b1c3", "g8f6", "d2d3", "d7d6"})
+	control = b.ControlMap(White)
+	d5 := b.AlgebraicToBit("d5")
+	if control[d5] != 2 {
+		t.Error("Expected 2 attackers of d5, got", control[d5])
+	}
+	for p := 32; p < 64; p++ {
+		if control[p] > control[d5] {
+			t.Error("Expected at most 2 attackers of", b.BitToAlgebraic(p), ", got", control[p])
+		}
+	}
+	// Symmetrically, Black attacks d4 with the e5 pawn and c6 knight.
+	control = b.ControlMap(Black)
+	if result := control[b.AlgebraicToBit("d4")]; result != 2 {
+		t.Error("Expected 2 attackers of d4, got", result)
+	}
+}
+
+func TestSpaceAdvantage(t *testing.T) {
+	if result := NewChessBoard().SpaceAdvantage(); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+	// White's pawns on d5 and e5 claim c6, d6, e6, and f6, and take away
+	// Black's control of d5 and e5.
+	b, _ := ParseFEN("4k3/pppppppp/8/3PP3/8/8/PPP2PPP/4K3 w")
+	space := b.SpaceAdvantage()
+	if space <= 0 {
+		t.Error("Expected White to have more space, got", space)
+	}
+	// The mirror image favours Black by the same amount.
+	b, _ = ParseFEN("4k3/ppp2ppp/8/8/3pp3/8/PPPPPPPP/4K3 w")
+	if result := b.SpaceAdvantage(); result != -space {
+		t.Error("Expected", -space, ", got", result)
+	}
+}
+
+func TestPassedPawns(t *testing.T) {
+	if result := NewChessBoard().PassedPawns(White); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+	// The pawn on a5 is passed. The pawn on e4 is blocked by e5, and the
+	// pawns on g2 and h3 hold each other back.
+	b, _ := ParseFEN("4k3/8/8/P3p3/4P3/1p5p/6P1/4K3 w")
+	expected := uint64(1) << 32
+	if result := b.PassedPawns(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	// Black's pawn on b3 is passed; a5 is behind it.
+	expected = uint64(1) << 17
+	if result := b.PassedPawns(Black); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestDoubledPawns(t *testing.T) {
+	if result := NewChessBoard().DoubledPawns(White); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+	// White's c-pawns are tripled and the e-pawn stands alone. Black's pawns
+	// on the b-file do not count against White.
+	b, _ := ParseFEN("4k3/1p6/1p6/2P5/8/2P1P3/2P5/4K3 w")
+	expected := uint64(1<<10 | 1<<18 | 1<<34)
+	if result := b.DoubledPawns(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	expected = uint64(1<<41 | 1<<49)
+	if result := b.DoubledPawns(Black); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestIsolatedPawns(t *testing.T) {
+	if result := NewChessBoard().IsolatedPawns(White); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+	// The pawns on a2 and e3 are isolated. The pawns on g2 and h2 support
+	// each other.
+	b, _ := ParseFEN("4k3/8/8/8/8/4P3/P5PP/4K3 w")
+	expected := uint64(1<<8 | 1<<20)
+	if result := b.IsolatedPawns(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	// A doubled pawn with no neighbours is isolated too.
+	b, _ = ParseFEN("4k3/8/8/8/8/P7/P7/4K3 w")
+	expected = uint64(1<<8 | 1<<16)
+	if result := b.IsolatedPawns(White); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestOpenFiles(t *testing.T) {
+	if result := NewChessBoard().OpenFiles(); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+	// The e-file is open, and Black's missing d-pawn leaves the d-file half
+	// open for Black.
+	b, _ := ParseFEN("4k3/ppp2ppp/8/8/8/8/PPPP1PPP/4K3 w")
+	eFile := uint64(0x1010101010101010)
+	dFile := uint64(0x0808080808080808)
+	if result := b.OpenFiles(); result != eFile {
+		t.Errorf("Expected %#016x, got %#016x", eFile, result)
+	}
+	if result := b.HalfOpenFiles(White); result != eFile {
+		t.Errorf("Expected %#016x, got %#016x", eFile, result)
+	}
+	if result := b.HalfOpenFiles(Black); result != dFile|eFile {
+		t.Errorf("Expected %#016x, got %#016x", dFile|eFile, result)
+	}
+}
+
+func TestHangingPieces(t *testing.T) {
+	if result := NewChessBoard().HangingPieces(White); result != 0 {
+		t.Errorf("Expected %#016x, got %#016x", 0, result)
+	}
+	// The knight on e5 is attacked by the rook on e8 and undefended. The
+	// bishop on c4 is attacked by the rook on c8 but defended by the pawn on b3.
+	b, _ := ParseFEN("2r1r2k/8/8/4N3/2B5/1P6/8/6K1 w")
+	if result := b.HangingPieces(White); result != 1<<36 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(1)<<36, result)
+	}
+	// Defending the knight leaves nothing hanging.
+	b.PlacePieceAlgebraic(WhitePawns, "d4")
+	if result := b.HangingPieces(White); result != 0 {
+		t.Errorf("Expected %#016x, got %#016x", 0, result)
+	}
+	// Neither rook is attacked.
+	if result := b.HangingPieces(Black); result != 0 {
+		t.Errorf("Expected %#016x, got %#016x", 0, result)
+	}
+}
+
+func TestForks(t *testing.T) {
+	if forks := NewChessBoard().Forks(White); len(forks) != 0 {
+		t.Error("Expected no forks, got", forks)
+	}
+	// Nc7+ forks the king on e8 and the queen on a8.
+	b, _ := ParseFEN("q3k3/8/8/1N6/8/8/8/4K3 w")
+	expected := []Move{{From: 33, To: 50, Piece: WhiteKnights}}
+	if forks := b.Forks(White); !reflect.DeepEqual(forks, expected) {
+		t.Error("Expected", expected, ", got", forks)
+	}
+	// A promotion counts the attacks of the new piece: a queen or rook on b8
+	// attacks both the king on e8 and the rook on b2, but a knight or bishop
+	// does not.
+	b, _ = ParseFEN("4k3/1P6/8/8/8/8/1r6/K7 w")
+	forks := b.Forks(White)
+	if len(forks) != 2 {
+		t.Fatal("Expected 2 forks, got", forks)
+	}
+	for _, m := range forks {
+		if m.From != 49 || m.To != 57 || (m.Promotion != PromoteQueen && m.Promotion != PromoteRook) {
+			t.Error("Expected b8=Q or b8=R, got", m)
+		}
+	}
+}
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		fen      string
+		from, to string
+		expected int
+	}{
+		// An undefended knight.
+		{"4k3/8/8/3n4/4P3/8/8/4K3 w", "e4", "d5", 320},
+		// A pawn defended by a pawn costs the queen.
+		{"4k3/8/2p5/3p4/8/8/3Q4/4K3 w", "d2", "d5", -800},
+		// A knight defended by a pawn is an even trade for a knight...
+		{"4k3/8/2p5/3n4/8/4N3/8/4K3 w", "e3", "d5", 0},
+		// ...but not for a rook.
+		{"4k3/8/2p5/3n4/8/8/8/3RK3 w", "d1", "d5", -180},
+		// The rook on d1 backs up the rook on d2, winning the pawn.
+		{"3rk3/8/8/3p4/8/8/3R4/3RK3 w", "d2", "d5", 100},
+		// The king recaptures an undefended bishop...
+		{"4k3/8/8/8/8/2b5/3p4/3RK3 w", "d1", "d2", -70},
+		// ...but not one defended by the rook behind the pawn.
+		{"3rk3/8/8/8/8/2b5/3p4/3RK3 w", "d1", "d2", -400},
+		// A quiet move onto a defended square loses the piece.
+		{"4k3/8/2p5/8/8/4N3/8/4K3 w", "e3", "d5", -320},
+	}
+	for _, test := range tests {
+		b, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if result := b.SEE(b.AlgebraicToBit(test.from), b.AlgebraicToBit(test.to)); result != test.expected {
+			t.Error("Expected", test.expected, "for", test.fen, ", got", result)
+		}
+	}
+	if result := NewChessBoard().SEE(32, 40); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+}
+
+func TestMobility(t *testing.T) {
+	b := NewChessBoard()
+	for _, player := range []int{White, Black} {
+		if result := b.Mobility(player); result != 20 {
+			t.Error("Expected 20, got", result)
+		}
+	}
+	// After 1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 White's pieces have more scope.
+	b, _ = NewChessBoardFromMoves([]string{"e2e4", "e7e5", "g1f3", "b8c6", "f1c4", "f8c5"})
+	if result := b.Mobility(White); result <= 20 {
+		t.Error("Expected mobility above 20, got", result)
+	}
+	// A rook on a1 reaches 13 squares alongside its king on h1, which
+	// reaches 3.
+	b, _ = ParseFEN("7k/8/8/8/8/8/8/R6K w")
+	if result := b.Mobility(White); result != 16 {
+		t.Error("Expected 16, got", result)
+	}
+}
+
+func TestMobilityDelta(t *testing.T) {
+	b := NewChessBoard()
+	hash := b.ZobristHash()
+	// Nf3 blocks the f-pawn but frees the rook and reaches five squares.
+	nf3 := b.MobilityDelta(Move{From: 6, To: 21, Piece: WhiteKnights}, White)
+	if nf3 != 2 {
+		t.Error("Expected 2, got", nf3)
+	}
+	// Na3 reaches fewer squares from the edge.
+	if result := b.MobilityDelta(Move{From: 1, To: 16, Piece: WhiteKnights}, White); result >= nf3 {
+		t.Error("Expected less than", nf3, ", got", result)
+	}
+	if b.ZobristHash() != hash || b.SideToMove != White {
+		t.Error("Expected the board to be unchanged")
+	}
+}
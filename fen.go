@@ -0,0 +1,263 @@
+package bitboard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseFEN constructs a chess board from a position in Forsyth-Edwards
+// Notation. The piece placement and active colour fields are required. The
+// castling availability, en passant target, halfmove clock, and fullmove
+// number fields may follow. The move counters default to 0 and 1.
+func ParseFEN(fen string) (*Bitboard, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 2 || len(fields) > 6 {
+		return nil, fmt.Errorf("bitboard: invalid FEN %q", fen)
+	}
+	b := NewChessBoard()
+	for i := range b.Bitmaps {
+		b.Bitmaps[i] = 0
+	}
+	b.RecomputeOccupied()
+	rows := strings.Split(fields[0], "/")
+	if len(rows) != b.Ranks {
+		return nil, fmt.Errorf("bitboard: invalid FEN piece placement %q", fields[0])
+	}
+	for i, row := range rows {
+		y := b.Ranks - 1 - i
+		x := 0
+		for _, c := range row {
+			if c >= '1' && c <= '8' {
+				x += int(c - '0')
+				continue
+			}
+			m := -1
+			for j, s := range b.Symbols {
+				if s == string(c) {
+					m = j
+				}
+			}
+			if m == -1 || x >= b.Files {
+				return nil, fmt.Errorf("bitboard: invalid FEN piece placement %q", fields[0])
+			}
+			if err := b.PlacePieceCartesian(m, x, y); err != nil {
+				return nil, err
+			}
+			x++
+		}
+		if x != b.Files {
+			return nil, fmt.Errorf("bitboard: invalid FEN piece placement %q", fields[0])
+		}
+	}
+	switch fields[1] {
+	case "w":
+		b.SideToMove = White
+	case "b":
+		b.SideToMove = Black
+	default:
+		return nil, fmt.Errorf("bitboard: invalid FEN active colour %q", fields[1])
+	}
+	b.CastlingRights = 0
+	if len(fields) > 2 && fields[2] != "-" {
+		for _, c := range fields[2] {
+			i := strings.IndexRune("KQkq", c)
+			if i == -1 || b.CastlingRights&(1<<uint(i)) != 0 {
+				return nil, fmt.Errorf("bitboard: invalid FEN castling availability %q", fields[2])
+			}
+			b.CastlingRights |= 1 << uint(i)
+		}
+	}
+	if len(fields) > 3 && fields[3] != "-" {
+		p, err := b.ParseAlgebraic(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("bitboard: invalid FEN en passant target %q", fields[3])
+		}
+		b.EnPassant = p
+	}
+	if len(fields) > 4 {
+		n, err := strconv.Atoi(fields[4])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("bitboard: invalid FEN halfmove clock %q", fields[4])
+		}
+		b.HalfmoveClock = n
+	}
+	if len(fields) > 5 {
+		n, err := strconv.Atoi(fields[5])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("bitboard: invalid FEN fullmove number %q", fields[5])
+		}
+		b.FullmoveNumber = n
+	}
+	return b, nil
+}
+
+// FEN returns the board's position in Forsyth-Edwards Notation, with all six
+// fields. A fullmove number below 1, as on boards not set up for chess, is
+// written as 1.
+func (b *Bitboard) FEN() string {
+	var s strings.Builder
+	for y := b.Ranks - 1; y >= 0; y-- {
+		empty := 0
+		for x := 0; x < b.Files; x++ {
+			symbol := b.SymbolAt(CartesianToBit(x, y, b.Files))
+			if symbol == "" {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				s.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			s.WriteString(symbol)
+		}
+		if empty > 0 {
+			s.WriteString(strconv.Itoa(empty))
+		}
+		if y > 0 {
+			s.WriteString("/")
+		}
+	}
+	if b.SideToMove == White {
+		s.WriteString(" w")
+	} else {
+		s.WriteString(" b")
+	}
+	s.WriteString(" ")
+	if b.CastlingRights == 0 {
+		s.WriteString("-")
+	}
+	for i, c := range "KQkq" {
+		if b.CastlingRights&(1<<uint(i)) != 0 {
+			s.WriteRune(c)
+		}
+	}
+	if b.EnPassant == -1 {
+		s.WriteString(" -")
+	} else {
+		s.WriteString(" " + b.BitToAlgebraic(b.EnPassant))
+	}
+	fullmove := b.FullmoveNumber
+	if fullmove < 1 {
+		fullmove = 1
+	}
+	fmt.Fprintf(&s, " %d %d", b.HalfmoveClock, fullmove)
+	return s.String()
+}
+
+// ParseEPD parses a line in Extended Position Description format. It returns
+// the board described by the four position fields and a map of each opcode
+// to its operand, with any surrounding quotes removed.
+func ParseEPD(line string) (*Bitboard, map[string]string, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil, nil, fmt.Errorf("bitboard: invalid EPD %q", line)
+	}
+	b, err := ParseFEN(strings.Join(fields[:4], " "))
+	if err != nil {
+		return nil, nil, err
+	}
+	// Skip past the position fields to the operations.
+	rest := line
+	for _, f := range fields[:4] {
+		rest = rest[strings.Index(rest, f)+len(f):]
+	}
+	ops := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		// Find the semicolon ending the operation, ignoring quoted ones.
+		end, quoted := -1, false
+		for i, c := range rest {
+			if c == '"' {
+				quoted = !quoted
+			} else if c == ';' && !quoted {
+				end = i
+				break
+			}
+		}
+		if end == -1 {
+			return nil, nil, fmt.Errorf("bitboard: unterminated EPD operation %q", rest)
+		}
+		op := strings.TrimSpace(rest[:end])
+		rest = rest[end+1:]
+		opcode, operand := op, ""
+		if i := strings.IndexAny(op, " \t"); i != -1 {
+			opcode, operand = op[:i], strings.TrimSpace(op[i:])
+		}
+		if !validOpcode(opcode) {
+			return nil, nil, fmt.Errorf("bitboard: invalid EPD opcode %q", opcode)
+		}
+		if len(operand) >= 2 && operand[0] == '"' && operand[len(operand)-1] == '"' {
+			operand = operand[1 : len(operand)-1]
+		}
+		ops[opcode] = operand
+	}
+	return b, ops, nil
+}
+
+// validOpcode reports whether s is a valid EPD opcode: a letter followed by
+// up to 14 letters, digits, or underscores.
+func validOpcode(s string) bool {
+	if len(s) == 0 || len(s) > 15 {
+		return false
+	}
+	for i, c := range s {
+		letter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || !(c >= '0' && c <= '9' || c == '_')) {
+			return false
+		}
+	}
+	return true
+}
+
+// A FENScanner reads chess positions from a stream with one position in
+// Forsyth-Edwards Notation per line. Blank lines are skipped. Scanning stops
+// at the end of the stream or at the first malformed line.
+type FENScanner struct {
+	scanner *bufio.Scanner
+	board   *Bitboard
+	line    int
+	err     error
+}
+
+// NewFENScanner returns a FENScanner reading from r.
+func NewFENScanner(r io.Reader) *FENScanner {
+	return &FENScanner{scanner: bufio.NewScanner(r)}
+}
+
+// Scan advances to the next position, which is then available through Board.
+// It returns false when scanning stops; Err reports whether this was due to
+// an error.
+func (s *FENScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.scanner.Scan() {
+		s.line++
+		text := strings.TrimSpace(s.scanner.Text())
+		if text == "" {
+			continue
+		}
+		s.board, s.err = ParseFEN(text)
+		if s.err != nil {
+			s.err = fmt.Errorf("%v on line %d", s.err, s.line)
+			s.board = nil
+			return false
+		}
+		return true
+	}
+	s.board = nil
+	s.err = s.scanner.Err()
+	return false
+}
+
+// Board returns the most recent position read by Scan.
+func (s *FENScanner) Board() *Bitboard {
+	return s.board
+}
+
+// Err returns the first error encountered by the FENScanner.
+func (s *FENScanner) Err() error {
+	return s.err
+}
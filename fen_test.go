@@ -0,0 +1,154 @@
+package bitboard
+
+import (
+	"strings"
+	"testing"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w"
+
+func TestParseFEN(t *testing.T) {
+	b, err := ParseFEN(startFEN + " KQkq - 0 1")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := NewChessBoard()
+	for i := range expected.Bitmaps {
+		if b.Bitmaps[i] != expected.Bitmaps[i] {
+			t.Errorf("Expected bitmap %d to be %#016x, got %#016x", i, expected.Bitmaps[i], b.Bitmaps[i])
+		}
+	}
+	if b.Occupied != expected.Occupied {
+		t.Errorf("Expected %#016x, got %#016x", expected.Occupied, b.Occupied)
+	}
+	b, err = ParseFEN("8/8/8/8/4P3/8/8/8 b")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if b.SideToMove != Black {
+		t.Error("Expected", Black, ", got", b.SideToMove)
+	}
+	if b.SymbolAtAlgebraic("e4") != "P" {
+		t.Error("Expected P, got", b.SymbolAtAlgebraic("e4"))
+	}
+}
+
+func TestParseFENInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN w",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNRR w",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - e9",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KK -",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KX -",
+	}
+	for _, fen := range invalid {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Error("Expected error for", fen)
+		}
+	}
+}
+
+func TestFENTopLeftOrigin(t *testing.T) {
+	b := NewChessBoard()
+	b.TopLeftOrigin = true
+	expected := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+}
+
+func TestFEN(t *testing.T) {
+	b := NewChessBoard()
+	expected := startFEN + " KQkq - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	fen := "r3k2r/8/8/3pP3/8/8/8/R3K2R w Kq d6 0 1"
+	b, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if result := b.FEN(); result != fen {
+		t.Error("Expected", fen, ", got", result)
+	}
+}
+
+func TestParseEPD(t *testing.T) {
+	line := `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`
+	b, ops, err := ParseEPD(line)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	expected := "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+	if ops["bm"] != "Qg6" || ops["id"] != "WAC.001" || len(ops) != 2 {
+		t.Error("Expected bm and id opcodes, got", ops)
+	}
+	line = `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - c0 "semi;colon"; hmvc 0; noop;`
+	_, ops, err = ParseEPD(line)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if ops["c0"] != "semi;colon" || ops["hmvc"] != "0" || ops["noop"] != "" {
+		t.Error("Expected c0, hmvc, and noop opcodes, got", ops)
+	}
+}
+
+func TestParseEPDInvalid(t *testing.T) {
+	invalid := []string{
+		"8/8/8/8/8/8/8/8 w -",
+		"8/8/8/8/8/8/8/8 w - - bm e4",
+		"8/8/8/8/8/8/8/8 w - - 1bm e4;",
+		"8/8/8/8/8/8/8/8 w - - b-m e4;",
+		`8/8/8/8/8/8/8/8 w - - id "unterminated;`,
+		"8/8/8/8/8/8/8/9 w - - bm e4;",
+	}
+	for _, line := range invalid {
+		if _, _, err := ParseEPD(line); err == nil {
+			t.Error("Expected error for", line)
+		}
+	}
+}
+
+func TestFENScanner(t *testing.T) {
+	input := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1\n" +
+		"\n" +
+		"4k3/8/8/8/8/8/8/4K3 b - -\n" +
+		"4k3/8/8/8/8/8/8/4K3 x - -\n" +
+		"4k3/8/8/8/8/8/8/4KQ2 w - -\n"
+	s := NewFENScanner(strings.NewReader(input))
+	var fens []string
+	for s.Scan() {
+		fens = append(fens, s.Board().FEN())
+	}
+	expected := []string{startFEN + " KQkq - 0 1", "4k3/8/8/8/8/8/8/4K3 b - - 0 1"}
+	if len(fens) != len(expected) {
+		t.Fatal("Expected", len(expected), "positions, got", len(fens))
+	}
+	for i := range expected {
+		if fens[i] != expected[i] {
+			t.Error("Expected", expected[i], ", got", fens[i])
+		}
+	}
+	if s.Err() == nil || !strings.HasSuffix(s.Err().Error(), "on line 4") {
+		t.Error("Expected error on line 4, got", s.Err())
+	}
+	if s.Scan() || s.Board() != nil {
+		t.Error("Expected scanning to stop after an error")
+	}
+	s = NewFENScanner(strings.NewReader(startFEN))
+	n := 0
+	for s.Scan() {
+		n++
+	}
+	if n != 1 || s.Err() != nil {
+		t.Error("Expected one position and no error, got", n, s.Err())
+	}
+}
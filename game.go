@@ -0,0 +1,91 @@
+package bitboard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Game couples a chess board with the moves played on it. Construct games
+// with NewGame, which records the starting position, and play them with Move
+// and Undo rather than by changing the board directly.
+type Game struct {
+	Board   *Bitboard // Current position
+	History []Move    // Moves played, in order
+	start   *Bitboard
+	undos   []Undo
+}
+
+// NewGame starts a game from a copy of position b.
+func NewGame(b *Bitboard) *Game {
+	return &Game{Board: b.Clone(), start: b.Clone()}
+}
+
+// Move plays move m for the side to move. It returns an error if m is not
+// legal. Moves are matched on their origin, destination, and promotion, so
+// the moving piece may be left as -1, as ParseUCIMove does.
+func (g *Game) Move(m Move) error {
+	for _, move := range g.Board.LegalMoves(g.Board.SideToMove) {
+		if move.From == m.From && move.To == m.To && move.Promotion == m.Promotion {
+			g.undos = append(g.undos, g.Board.MakeMove(move))
+			g.History = append(g.History, move)
+			return nil
+		}
+	}
+	return fmt.Errorf("bitboard: illegal move %v", m)
+}
+
+// Undo takes back the last move played. It returns an error if no moves have
+// been played.
+func (g *Game) Undo() error {
+	n := len(g.History)
+	if n == 0 {
+		return errors.New("bitboard: no moves to undo")
+	}
+	g.Board.UnmakeMove(g.History[n-1], g.undos[n-1])
+	g.History, g.undos = g.History[:n-1], g.undos[:n-1]
+	return nil
+}
+
+// Result returns the result of the game as written in PGN: "1-0" or "0-1" if
+// a player has been checkmated, "1/2-1/2" for a stalemate, when neither
+// player has sufficient material to checkmate, or under the fifty-move rule,
+// or "*" if the game is still in progress.
+func (g *Game) Result() string {
+	player := g.Board.SideToMove
+	switch {
+	case g.Board.IsCheckmate(player) && player == White:
+		return "0-1"
+	case g.Board.IsCheckmate(player):
+		return "1-0"
+	case g.Board.IsStalemate(player), g.Board.InsufficientMaterial(), g.Board.FiftyMoveDraw():
+		return "1/2-1/2"
+	}
+	return "*"
+}
+
+// PGN returns the game's moves as Portable Game Notation movetext with move
+// numbers (e.g., "1. e4 e5 2. Nf3"), counting from the starting position's
+// fullmove number. A game that starts with Black to move opens with an
+// ellipsis (e.g., "1... e5").
+func (g *Game) PGN() string {
+	b := g.start.Clone()
+	var s []string
+	n := b.FullmoveNumber
+	if n < 1 {
+		n = 1
+	}
+	for i, m := range g.History {
+		if b.SideToMove == White {
+			s = append(s, fmt.Sprintf("%d.", n))
+		} else if i == 0 {
+			s = append(s, fmt.Sprintf("%d...", n))
+		}
+		s = append(s, b.SAN(m))
+		b.MakeMove(m)
+		if b.SideToMove == White {
+			n++
+		}
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,82 @@
+package bitboard
+
+import "testing"
+
+func TestPGN(t *testing.T) {
+	g := NewGame(NewChessBoard())
+	if result := g.PGN(); result != "" {
+		t.Error("Expected empty movetext, got", result)
+	}
+	for _, s := range []string{"e4", "e5", "Nf3"} {
+		from, to, m, err := g.Board.ParseSAN(s, g.Board.SideToMove)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if err := g.Move(Move{From: from, To: to, Piece: m}); err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+	}
+	if result := g.PGN(); result != "1. e4 e5 2. Nf3" {
+		t.Error("Expected 1. e4 e5 2. Nf3, got", result)
+	}
+	// A game starting with Black to move.
+	b, _ := ParseFEN("4k3/8/8/8/8/8/4P3/4K3 b")
+	g = NewGame(b)
+	for _, move := range []Move{{From: 60, To: 59, Piece: BlackKing}, {From: 12, To: 28, Piece: WhitePawns}} {
+		if err := g.Move(move); err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+	}
+	if result := g.PGN(); result != "1... Kd8 2. e4" {
+		t.Error("Expected 1... Kd8 2. e4, got", result)
+	}
+}
+
+func TestGame(t *testing.T) {
+	g := NewGame(NewChessBoard())
+	start := g.Board.ZobristHash()
+	// Fool's mate.
+	for _, s := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		m, err := ParseUCIMove(s)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if err := g.Move(m); err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+	}
+	if len(g.History) != 4 {
+		t.Error("Expected 4 moves, got", len(g.History))
+	}
+	if result := g.Result(); result != "0-1" {
+		t.Error("Expected 0-1, got", result)
+	}
+	if err := g.Move(Move{From: 12, To: 28}); err == nil {
+		t.Error("Expected error for move after checkmate")
+	}
+	if err := g.Undo(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if len(g.History) != 3 || g.Result() != "*" {
+		t.Error("Expected game in progress after 3 moves, got", len(g.History), g.Result())
+	}
+	for len(g.History) > 0 {
+		g.Undo()
+	}
+	if g.Board.ZobristHash() != start || g.Board.SideToMove != White {
+		t.Error("Expected the starting position to be restored")
+	}
+	if err := g.Undo(); err == nil {
+		t.Error("Expected error undoing with no moves")
+	}
+	// Stalemate.
+	b, _ := ParseFEN("7k/8/6Q1/8/8/8/8/K7 b")
+	if result := NewGame(b).Result(); result != "1/2-1/2" {
+		t.Error("Expected 1/2-1/2, got", result)
+	}
+	// Insufficient material.
+	b, _ = ParseFEN("4k3/8/8/8/8/8/8/4KN2 w")
+	if result := NewGame(b).Result(); result != "1/2-1/2" {
+		t.Error("Expected 1/2-1/2, got", result)
+	}
+}
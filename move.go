@@ -0,0 +1,433 @@
+package bitboard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Move describes a piece moving from one square to another.
+type Move struct {
+	From      int    // Bit position the piece moves from
+	To        int    // Bit position the piece moves to
+	Piece     int    // Index of the moving piece's bitmap
+	Promotion int    // Piece a pawn promotes to, or NoPromotion
+	Jumped    uint64 // Pieces jumped over and captured (checkers)
+}
+
+// Promotion choices for chess pawns. Each choice is one greater than the
+// corresponding piece, so the zero value means no promotion. PromoteKing marks
+// a checkers man being crowned.
+const (
+	NoPromotion = iota
+	PromoteRook
+	PromoteKnight
+	PromoteBishop
+	PromoteQueen
+	PromoteKing
+)
+
+// UCI returns the move in the long algebraic notation used by the Universal
+// Chess Interface (e.g., "e2e4", or "e7e8q" for a promotion). Castling is
+// written as the king's move.
+func (m Move) UCI() string {
+	s := BitToAlgebraic(m.From, 8) + BitToAlgebraic(m.To, 8)
+	if m.Promotion != NoPromotion {
+		s += string("rnbqk"[m.Promotion-1])
+	}
+	return s
+}
+
+// String returns the move in coordinate notation, as UCI does. Moves are
+// comparable, so two moves are equal when all their fields match.
+func (m Move) String() string {
+	return m.UCI()
+}
+
+// ParseUCIMove parses a move in Universal Chess Interface notation. The
+// notation does not identify the moving piece, so the returned move's Piece
+// is -1; look it up on the board with GetBitmapIndex.
+func ParseUCIMove(s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, fmt.Errorf("bitboard: invalid UCI move %q", s)
+	}
+	from, err := ParseAlgebraic(s[0:2], 8, 8)
+	if err != nil {
+		return Move{}, fmt.Errorf("bitboard: invalid UCI move %q", s)
+	}
+	to, err := ParseAlgebraic(s[2:4], 8, 8)
+	if err != nil {
+		return Move{}, fmt.Errorf("bitboard: invalid UCI move %q", s)
+	}
+	m := Move{From: from, To: to, Piece: -1}
+	if len(s) == 5 {
+		m.Promotion = strings.IndexByte("rnbq", s[4]) + 1
+		if m.Promotion == NoPromotion {
+			return Move{}, fmt.Errorf("bitboard: invalid UCI move %q", s)
+		}
+	}
+	return m, nil
+}
+
+// ParseMoveCoord parses a move given as a pair of coordinates in algebraic
+// notation (e.g., "e2e4"), optionally followed by a promotion piece (e.g.,
+// "e7e8q"), and returns the origin and destination bit positions. Each
+// coordinate is validated against the board's dimensions. Use ParseCoordMove
+// to learn which piece a pawn promotes to.
+func (b *Bitboard) ParseMoveCoord(s string) (from, to int, err error) {
+	m, err := b.ParseCoordMove(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.From, m.To, nil
+}
+
+// ParseCoordMove parses a move in coordinate notation like ParseMoveCoord, but
+// returns the whole Move. Its Piece is the bitmap holding the origin square,
+// or -1 if the square is empty, and a promotion piece is filled in as
+// ParseUCIMove does.
+func (b *Bitboard) ParseCoordMove(s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, fmt.Errorf("bitboard: invalid move %q", s)
+	}
+	from, err := b.ParseAlgebraic(s[0:2])
+	if err != nil {
+		return Move{}, fmt.Errorf("bitboard: invalid move %q: %v", s, err)
+	}
+	to, err := b.ParseAlgebraic(s[2:4])
+	if err != nil {
+		return Move{}, fmt.Errorf("bitboard: invalid move %q: %v", s, err)
+	}
+	m := Move{From: from, To: to, Piece: b.GetBitmapIndex(from)}
+	if len(s) == 5 {
+		m.Promotion = strings.IndexByte("rnbq", strings.ToLower(s[4:])[0]) + 1
+		if m.Promotion == NoPromotion {
+			return Move{}, fmt.Errorf("bitboard: invalid promotion in move %q", s)
+		}
+	}
+	return m, nil
+}
+
+// Undo holds the state needed to take back a move with UnmakeMove.
+type Undo struct {
+	Captured       int    // Index of the captured piece's bitmap, or -1
+	EnPassant      int    // En passant target square before the move
+	CastlingRights uint8  // Castling availability before the move
+	Kings          uint64 // Crowned checkers pieces before the move
+	HalfmoveClock  int    // Halfmove clock before the move
+}
+
+// MakeMove plays a move, capturing any piece on the destination square, and
+// passes the turn to the other player. It does not check that the move is
+// legal. The returned Undo takes the move back when passed to UnmakeMove.
+//
+// A chess pawn moving onto the en passant target square captures the pawn
+// that just passed it, and a double pawn push sets the en passant target.
+// Moving a king or rook, or capturing a rook, revokes castling rights. A king
+// moving two squares castles, taking the rook with it. A promoting pawn is
+// replaced by the chosen piece. The halfmove clock is reset by pawn moves and
+// captures and advanced by any other move, and the fullmove number is
+// advanced after Black moves.
+//
+// A checkers move captures the pieces it jumps instead of any piece on the
+// destination square. A king keeps its crown as it moves, and a man flagged
+// with PromoteKing is crowned.
+func (b *Bitboard) MakeMove(m Move) Undo {
+	u := Undo{Captured: -1, EnPassant: b.EnPassant, CastlingRights: b.CastlingRights, Kings: b.Kings, HalfmoveClock: b.HalfmoveClock}
+	if m.Jumped != 0 {
+		u.Captured = b.GetBitmapIndex(LSB(m.Jumped))
+		for jumped := m.Jumped; jumped != 0; jumped &= jumped - 1 {
+			b.RemovePieceBit(u.Captured, LSB(jumped))
+		}
+	} else {
+		p := enPassantCapture(m, b.EnPassant)
+		if p == -1 {
+			p = m.To
+		}
+		u.Captured = b.GetBitmapIndex(p)
+		if u.Captured != -1 {
+			b.RemovePieceBit(u.Captured, p)
+		}
+	}
+	b.movePieceBit(m.Piece, m.From, m.To)
+	if from, to := castlingRook(m); from != -1 {
+		b.movePieceBit(m.Piece-King+Rook, from, to)
+	}
+	if b.Kings != 0 || m.Promotion == PromoteKing {
+		crowned := IsBitSet(b.Kings, m.From) || m.Promotion == PromoteKing
+		b.Kings &^= m.Jumped | uint64(1)<<uint(m.From) | uint64(1)<<uint(m.To)
+		if crowned {
+			SetBit(&b.Kings, m.To)
+		}
+	}
+	if m.Promotion != NoPromotion && m.Promotion != PromoteKing {
+		b.RemovePieceBit(m.Piece, m.To)
+		b.placePieceBit(promotedPiece(m), m.To)
+	}
+	b.EnPassant = -1
+	if m.Piece%6 == Pawn && (m.To-m.From == 16 || m.From-m.To == 16) {
+		b.EnPassant = (m.From + m.To) / 2
+	}
+	b.CastlingRights &^= castlingMasks[m.From] | castlingMasks[m.To]
+	b.HalfmoveClock++
+	if m.Piece%6 == Pawn || u.Captured != -1 {
+		b.HalfmoveClock = 0
+	}
+	if b.SideToMove == Black {
+		b.FullmoveNumber++
+	}
+	b.SideToMove ^= 1
+	return u
+}
+
+// UnmakeMove takes back a move played with MakeMove.
+func (b *Bitboard) UnmakeMove(m Move, u Undo) {
+	b.SideToMove ^= 1
+	if b.SideToMove == Black {
+		b.FullmoveNumber--
+	}
+	b.EnPassant = u.EnPassant
+	b.CastlingRights = u.CastlingRights
+	b.Kings = u.Kings
+	b.HalfmoveClock = u.HalfmoveClock
+	if m.Promotion != NoPromotion && m.Promotion != PromoteKing {
+		b.RemovePieceBit(promotedPiece(m), m.To)
+		b.placePieceBit(m.Piece, m.To)
+	}
+	b.movePieceBit(m.Piece, m.To, m.From)
+	if from, to := castlingRook(m); from != -1 {
+		b.movePieceBit(m.Piece-King+Rook, to, from)
+	}
+	if m.Jumped != 0 {
+		for jumped := m.Jumped; jumped != 0; jumped &= jumped - 1 {
+			b.placePieceBit(u.Captured, LSB(jumped))
+		}
+	} else if u.Captured != -1 {
+		p := enPassantCapture(m, u.EnPassant)
+		if p == -1 {
+			p = m.To
+		}
+		b.placePieceBit(u.Captured, p)
+	}
+}
+
+// MakeNullMove passes the turn to the other player without moving a piece, as
+// search algorithms do for null-move pruning. Any en passant target is
+// cleared. The returned Undo takes the null move back when passed to
+// UnmakeNullMove.
+func (b *Bitboard) MakeNullMove() Undo {
+	u := Undo{Captured: -1, EnPassant: b.EnPassant, CastlingRights: b.CastlingRights, Kings: b.Kings, HalfmoveClock: b.HalfmoveClock}
+	b.EnPassant = -1
+	b.SideToMove ^= 1
+	return u
+}
+
+// UnmakeNullMove takes back a null move played with MakeNullMove.
+func (b *Bitboard) UnmakeNullMove(u Undo) {
+	b.SideToMove ^= 1
+	b.EnPassant = u.EnPassant
+}
+
+// Apply plays each move received from moves with MakeMove, in a separate
+// goroutine, until the channel is closed. If validate is not nil, each move is
+// first passed to it, and a move it rejects is skipped and its error sent on
+// the returned channel. The returned channel is closed once every move has
+// been handled; it must be drained, and the board must not be used elsewhere
+// until then. validate may inspect the board.
+func (b *Bitboard) Apply(moves <-chan Move, validate func(Move) error) <-chan error {
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+		for m := range moves {
+			if validate != nil {
+				if err := validate(m); err != nil {
+					errs <- fmt.Errorf("bitboard: move %v rejected: %v", m, err)
+					continue
+				}
+			}
+			b.MakeMove(m)
+		}
+	}()
+	return errs
+}
+
+// promotedPiece returns the index of the bitmap a promoting pawn joins.
+func promotedPiece(m Move) int {
+	return m.Piece - Pawn + m.Promotion - 1
+}
+
+// enPassantCapture returns the square of the pawn captured en passant by a
+// move, given the en passant target square before the move, or -1 if the move
+// is not an en passant capture.
+func enPassantCapture(m Move, enPassant int) int {
+	if m.Piece%6 != Pawn || m.To != enPassant || (m.To-m.From)%8 == 0 {
+		return -1
+	}
+	if m.To > m.From {
+		return m.To - 8
+	}
+	return m.To + 8
+}
+
+// castlingRook returns the origin and destination squares of the rook that
+// accompanies a castling move, or -1 and -1 if the move is not castling.
+func castlingRook(m Move) (int, int) {
+	switch {
+	case m.Piece%6 != King:
+		return -1, -1
+	case m.To-m.From == 2:
+		return m.From + 3, m.From + 1
+	case m.From-m.To == 2:
+		return m.From - 4, m.From - 1
+	}
+	return -1, -1
+}
+
+// castlingMoves generates a chess player's castling moves. Castling requires
+// the right to castle, no pieces between the king and rook, and that the king
+// neither starts on, crosses, nor lands on a square the other player attacks.
+func (b *Bitboard) castlingMoves(player int) []Move {
+	var moves []Move
+	m := player*6 + King
+	home := 0
+	if player == Black {
+		home = 56
+	}
+	king := home + 4
+	if !IsBitSet(b.Bitmaps[m], king) {
+		return nil
+	}
+	sides := []struct {
+		right   uint8
+		rook    int
+		between uint64
+		step    int
+	}{
+		{WhiteKingside << uint(2*player), home + 7, uint64(0x60) << uint(home), 1},
+		{WhiteQueenside << uint(2*player), home, uint64(0x0e) << uint(home), -1},
+	}
+	for _, s := range sides {
+		if b.CastlingRights&s.right == 0 || !IsBitSet(b.Bitmaps[player*6+Rook], s.rook) || b.Occupied&s.between != 0 {
+			continue
+		}
+		if b.IsSquareAttacked(king, player^1) || b.IsSquareAttacked(king+s.step, player^1) || b.IsSquareAttacked(king+2*s.step, player^1) {
+			continue
+		}
+		moves = append(moves, Move{From: king, To: king + 2*s.step, Piece: m})
+	}
+	return moves
+}
+
+// pseudoLegalMoves generates a chess player's moves without checking whether
+// they leave the player's own king in check. Castling moves are fully
+// checked.
+func (b *Bitboard) pseudoLegalMoves(player int) []Move {
+	var moves []Move
+	for m := player * 6; m < player*6+6; m++ {
+		for pieces := b.Bitmaps[m]; pieces != 0; pieces &= pieces - 1 {
+			from := LSB(pieces)
+			for targets := b.destinations(m, from); targets != 0; targets &= targets - 1 {
+				to := LSB(targets)
+				if m%6 == Pawn && (to < 8 || to > 55) {
+					for p := PromoteRook; p <= PromoteQueen; p++ {
+						moves = append(moves, Move{From: from, To: to, Piece: m, Promotion: p})
+					}
+					continue
+				}
+				moves = append(moves, Move{From: from, To: to, Piece: m})
+			}
+		}
+	}
+	return append(moves, b.castlingMoves(player)...)
+}
+
+// destinations returns the squares the chess piece of bitmap m on square p can
+// move to, without checking whether the moves leave its king in check.
+func (b *Bitboard) destinations(m int, p int) uint64 {
+	player := m / 6
+	targets := b.attacksFrom(m, p) &^ b.PlayerMask(player)
+	if m%6 == Pawn {
+		targets = b.pawnPushes(p, player) | targets&(b.PlayerMask(player^1)|b.enPassantMask())
+	}
+	return targets
+}
+
+// pawnPushes returns the empty squares a chess player's pawn on p can advance
+// to, including the double step from its starting rank.
+func (b *Bitboard) pawnPushes(p int, player int) uint64 {
+	var pushes uint64
+	step, start := 8, 1
+	if player == Black {
+		step, start = -8, 6
+	}
+	to := p + step
+	if to < 0 || to > 63 || IsBitSet(b.Occupied, to) {
+		return 0
+	}
+	SetBit(&pushes, to)
+	if p/8 == start && !IsBitSet(b.Occupied, to+step) {
+		SetBit(&pushes, to+step)
+	}
+	return pushes
+}
+
+// enPassantMask returns a bitmap of the en passant target square, if any.
+func (b *Bitboard) enPassantMask() uint64 {
+	var mask uint64
+	if b.EnPassant != -1 {
+		SetBit(&mask, b.EnPassant)
+	}
+	return mask
+}
+
+// LegalMoves generates a chess player's legal moves. It discards pseudo-legal
+// moves that leave the player's own king in check. Unless the player is in
+// check, only moves by the king or a pinned piece, and en passant captures,
+// can do so, and only these are made in turn to find out.
+func (b *Bitboard) LegalMoves(player int) []Move {
+	var legal []Move
+	pinned := b.PinnedPieces(player)
+	check := b.InCheck(player)
+	for _, m := range b.pseudoLegalMoves(player) {
+		if !check && m.Piece%6 != King && !IsBitSet(pinned, m.From) && enPassantCapture(m, b.EnPassant) == -1 {
+			legal = append(legal, m)
+			continue
+		}
+		u := b.MakeMove(m)
+		if !b.InCheck(player) {
+			legal = append(legal, m)
+		}
+		b.UnmakeMove(m, u)
+	}
+	return legal
+}
+
+// DestinationsFrom returns a bitmap of the squares the chess piece on pos can
+// legally move to, or 0 if pos is empty.
+func (b *Bitboard) DestinationsFrom(pos int) uint64 {
+	m := b.GetBitmapIndex(pos)
+	if m == -1 {
+		return 0
+	}
+	var mask uint64
+	for _, move := range b.LegalMoves(m / 6) {
+		if move.From == pos {
+			SetBit(&mask, move.To)
+		}
+	}
+	return mask
+}
+
+// MoveStrings returns a chess player's legal moves formatted for humans, with
+// the origin and destination squares separated by a hyphen (e.g., "e2-e4").
+// Promotions are suffixed with the chosen piece (e.g., "e7-e8=Q").
+func (b *Bitboard) MoveStrings(player int) []string {
+	var moves []string
+	for _, m := range b.LegalMoves(player) {
+		s := b.BitToAlgebraic(m.From) + "-" + b.BitToAlgebraic(m.To)
+		if m.Promotion != NoPromotion {
+			s += "=" + string("RNBQ"[m.Promotion-1])
+		}
+		moves = append(moves, s)
+	}
+	return moves
+}
@@ -0,0 +1,205 @@
+package bitboard
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUCI(t *testing.T) {
+	tests := map[string]Move{
+		"e2e4":  {From: 12, To: 28, Piece: WhitePawns},
+		"g8f6":  {From: 62, To: 45, Piece: BlackKnights},
+		"e1g1":  {From: 4, To: 6, Piece: WhiteKing},
+		"e8c8":  {From: 60, To: 58, Piece: BlackKing},
+		"a7a8q": {From: 48, To: 56, Piece: WhitePawns, Promotion: PromoteQueen},
+		"b2a1n": {From: 9, To: 0, Piece: BlackPawns, Promotion: PromoteKnight},
+	}
+	for s, m := range tests {
+		if result := m.UCI(); result != s {
+			t.Error("Expected", s, ", got", result)
+		}
+		parsed, err := ParseUCIMove(s)
+		if err != nil {
+			t.Error("Expected no error, got", err)
+		}
+		m.Piece = -1
+		if parsed != m {
+			t.Error("Expected", m, ", got", parsed)
+		}
+		if parsed.UCI() != s {
+			t.Error("Expected", s, ", got", parsed.UCI())
+		}
+	}
+}
+
+func TestMoveString(t *testing.T) {
+	m := Move{From: 12, To: 28, Piece: WhitePawns}
+	if result := m.String(); result != "e2e4" {
+		t.Error("Expected e2e4, got", result)
+	}
+	m = Move{From: 52, To: 60, Piece: WhitePawns, Promotion: PromoteRook}
+	if result := fmt.Sprint(m); result != "e7e8r" {
+		t.Error("Expected e7e8r, got", result)
+	}
+	if m != (Move{From: 52, To: 60, Piece: WhitePawns, Promotion: PromoteRook}) {
+		t.Error("Expected moves to be equal")
+	}
+	if m == (Move{From: 52, To: 60, Piece: WhitePawns, Promotion: PromoteQueen}) {
+		t.Error("Expected moves with different promotions to differ")
+	}
+}
+
+func TestParseUCIMoveInvalid(t *testing.T) {
+	for _, s := range []string{"", "e2", "e2e", "e2e4qq", "e2e9", "i2e4", "e7e8k", "0000"} {
+		if _, err := ParseUCIMove(s); err == nil {
+			t.Error("Expected error for", s)
+		}
+	}
+}
+
+func TestParseMoveCoord(t *testing.T) {
+	b := NewChessBoard()
+	var tests = []struct {
+		s        string
+		from, to int
+	}{
+		{"e2e4", 12, 28},
+		{"g1f3", 6, 21},
+		{"a7a8q", 48, 56},
+		{"B7B8N", 49, 57},
+	}
+	for _, test := range tests {
+		from, to, err := b.ParseMoveCoord(test.s)
+		if err != nil {
+			t.Error("Expected no error for", test.s, ", got", err)
+			continue
+		}
+		if from != test.from || to != test.to {
+			t.Error("Expected", test.from, test.to, ", got", from, to)
+		}
+	}
+	for _, s := range []string{"", "e2", "e2e", "e2e4qq", "e2e9", "i2e4", "e7e8k", "0000"} {
+		if _, _, err := b.ParseMoveCoord(s); err == nil {
+			t.Error("Expected error for", s)
+		}
+	}
+	// The promotion piece is kept in the parsed Move.
+	b, _ = ParseFEN("4k3/P7/8/8/8/8/8/4K3 w - -")
+	var promotions = []struct {
+		s        string
+		expected Move
+	}{
+		{"a7a8q", Move{From: 48, To: 56, Piece: WhitePawns, Promotion: PromoteQueen}},
+		{"A7A8N", Move{From: 48, To: 56, Piece: WhitePawns, Promotion: PromoteKnight}},
+		{"a7a8", Move{From: 48, To: 56, Piece: WhitePawns}},
+		{"b7b8r", Move{From: 49, To: 57, Piece: -1, Promotion: PromoteRook}},
+	}
+	for _, test := range promotions {
+		if result, err := b.ParseCoordMove(test.s); err != nil || result != test.expected {
+			t.Error("Expected", test.expected, ", got", result, err)
+		}
+	}
+	// Coordinates are checked against the board's dimensions.
+	c := NewConnectFourBoard()
+	if _, _, err := c.ParseMoveCoord("g1g6"); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if _, _, err := c.ParseMoveCoord("h1h2"); err == nil {
+		t.Error("Expected error for h1h2")
+	}
+}
+
+func TestDestinationsFrom(t *testing.T) {
+	b := NewChessBoard()
+	expected := uint64(1<<16 | 1<<18) // a3, c3
+	if result := b.DestinationsFrom(b.AlgebraicToBit("b1")); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	if result := b.DestinationsFrom(b.AlgebraicToBit("e4")); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+	// The bishop on d2 is pinned by the bishop on a5 and may only move along
+	// the pin.
+	b, _ = ParseFEN("4k3/8/8/b7/8/8/3B4/4K3 w")
+	expected = uint64(1<<18 | 1<<25 | 1<<32) // c3, b4, a5
+	if result := b.DestinationsFrom(b.AlgebraicToBit("d2")); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestApply(t *testing.T) {
+	b := NewChessBoard()
+	legal := func(m Move) error {
+		for _, l := range b.LegalMoves(b.SideToMove) {
+			if l == m {
+				return nil
+			}
+		}
+		return errors.New("illegal move")
+	}
+	moves := make(chan Move)
+	errs := b.Apply(moves, legal)
+	go func() {
+		moves <- Move{From: 12, To: 28, Piece: WhitePawns} // e2e4
+		moves <- Move{From: 51, To: 27, Piece: BlackPawns} // d7d4 is illegal
+		moves <- Move{From: 51, To: 35, Piece: BlackPawns} // d7d5
+		moves <- Move{From: 28, To: 35, Piece: WhitePawns} // e4xd5
+		close(moves)
+	}()
+	var rejected []error
+	for err := range errs {
+		rejected = append(rejected, err)
+	}
+	if len(rejected) != 1 || !strings.Contains(rejected[0].Error(), "d7d4") {
+		t.Error("Expected d7d4 to be rejected, got", rejected)
+	}
+	expected := "rnbqkbnr/ppp1pppp/8/3P4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2"
+	if result := b.FEN(); result != expected {
+		t.Error("Expected", expected, ", got", result)
+	}
+}
+
+func TestMoveStrings(t *testing.T) {
+	moves := NewChessBoard().MoveStrings(White)
+	if len(moves) != 20 {
+		t.Error("Expected 20, got", len(moves))
+	}
+	found := map[string]bool{}
+	for _, s := range moves {
+		found[s] = true
+	}
+	for _, s := range []string{"e2-e4", "e2-e3", "g1-f3", "b1-a3"} {
+		if !found[s] {
+			t.Error("Expected", s, "in", moves)
+		}
+	}
+	b, _ := ParseFEN("4k3/P7/8/8/8/8/8/K7 w")
+	found = map[string]bool{}
+	for _, s := range b.MoveStrings(White) {
+		found[s] = true
+	}
+	for _, s := range []string{"a7-a8=Q", "a7-a8=N", "a1-b2"} {
+		if !found[s] {
+			t.Error("Expected", s, "in", found)
+		}
+	}
+}
+
+func TestNullMove(t *testing.T) {
+	b, _ := ParseFEN("rnbqkbnr/pppp1ppp/8/8/3Pp3/8/PPP1PPPP/RNBQKBNR b KQkq d3")
+	before := b.Clone()
+	u := b.MakeNullMove()
+	if b.SideToMove != White || b.EnPassant != -1 {
+		t.Error("Expected White to move with no en passant target, got", b.SideToMove, b.EnPassant)
+	}
+	if !reflect.DeepEqual(b.Bitmaps, before.Bitmaps) || b.Occupied != before.Occupied || b.CastlingRights != before.CastlingRights {
+		t.Error("Expected no pieces to move")
+	}
+	b.UnmakeNullMove(u)
+	if !reflect.DeepEqual(b, before) {
+		t.Error("Expected", before, ", got", b)
+	}
+}
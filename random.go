@@ -0,0 +1,34 @@
+package bitboard
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// RandomBoard constructs a board with the given dimensions and number of
+// bitmaps, and scatters pieces at random so that each square is either empty
+// or held by exactly one bitmap. The same source produces the same board,
+// which makes it suitable for fuzzing. Bitmaps are given the symbols "A",
+// "B", "C", and so on.
+func RandomBoard(r *rand.Rand, ranks int, files int, bitmaps int) *Bitboard {
+	b := &Bitboard{
+		Bitmaps:   make([]uint64, bitmaps),
+		Symbols:   make([]string, bitmaps),
+		Ranks:     ranks,
+		Files:     files,
+		EnPassant: -1,
+	}
+	for i := range b.Symbols {
+		if i < 26 {
+			b.Symbols[i] = string(rune('A' + i))
+		} else {
+			b.Symbols[i] = strconv.Itoa(i)
+		}
+	}
+	for p := 0; p < ranks*files; p++ {
+		if i := r.Intn(bitmaps+1) - 1; i != -1 {
+			b.placePieceBit(i, p)
+		}
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package bitboard
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomBoard(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		ranks, files := 1+r.Intn(8), 1+r.Intn(8)
+		b := RandomBoard(r, ranks, files, 1+r.Intn(12))
+		if err := b.CheckInvariants(); err != nil {
+			t.Error("Expected no error for seed", seed, ", got", err)
+		}
+		if b.Occupied&^b.boardMask() != 0 {
+			t.Errorf("Expected pieces within %dx%d board, got %#016x", ranks, files, b.Occupied)
+		}
+	}
+	// The same seed produces the same board.
+	a := RandomBoard(rand.New(rand.NewSource(42)), 8, 8, 12)
+	b := RandomBoard(rand.New(rand.NewSource(42)), 8, 8, 12)
+	if a.Occupied != b.Occupied || a.ZobristHash() != b.ZobristHash() {
+		t.Error("Expected identical boards for identical seeds")
+	}
+	if a.Symbols[0] != "A" || a.Symbols[11] != "L" {
+		t.Error("Expected lettered symbols, got", a.Symbols)
+	}
+}
+
+func TestFlippedInvolution(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		b := RandomBoard(r, 1+r.Intn(8), 1+r.Intn(8), 3)
+		f := b.Flipped().Flipped()
+		for i := range b.Bitmaps {
+			if f.Bitmaps[i] != b.Bitmaps[i] {
+				t.Errorf("Expected flipping twice to be identity for seed %d, got %#016x", seed, f.Bitmaps[i])
+			}
+		}
+		if b.Ranks == 8 && b.Files == 8 && b.Flipped().Occupied != Rotate180(b.Occupied) {
+			t.Error("Expected Flipped to agree with Rotate180 for seed", seed)
+		}
+	}
+}
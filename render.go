@@ -0,0 +1,57 @@
+package bitboard
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTML returns the board as an HTML table with the eighth rank (or the top
+// rank of a smaller board) first, so a1 sits at the bottom left. Each cell
+// is classed "light" or "dark" by square colour, names its square in a
+// data-square attribute, and holds the symbol of the piece on it, if any.
+func (b *Bitboard) HTML() string {
+	var s strings.Builder
+	dark := DarkSquares(b.Files, b.Ranks)
+	s.WriteString("<table class=\"bitboard\">\n")
+	for y := b.Ranks - 1; y >= 0; y-- {
+		s.WriteString("<tr>")
+		for x := 0; x < b.Files; x++ {
+			p := CartesianToBit(x, y, b.Files)
+			class := "light"
+			if IsBitSet(dark, p) {
+				class = "dark"
+			}
+			s.WriteString("<td class=\"" + class + "\" data-square=\"" + b.BitToAlgebraic(p) + "\">")
+			s.WriteString(html.EscapeString(b.SymbolAt(p)))
+			s.WriteString("</td>")
+		}
+		s.WriteString("</tr>\n")
+	}
+	s.WriteString("</table>\n")
+	return s.String()
+}
+
+// SVG returns the board drawn as an SVG image with squares squareSize units
+// wide, a1 at the bottom left. Each square is a rectangle classed "light" or
+// "dark", and each piece is a text node holding its symbol.
+func (b *Bitboard) SVG(squareSize int) string {
+	var s strings.Builder
+	dark := DarkSquares(b.Files, b.Ranks)
+	width, height := b.Files*squareSize, b.Ranks*squareSize
+	fmt.Fprintf(&s, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	for p := 0; p < b.Ranks*b.Files; p++ {
+		x, y := BitToCartesian(p, b.Files)
+		left, top := x*squareSize, (b.Ranks-1-y)*squareSize
+		class, fill := "light", "#f0d9b5"
+		if IsBitSet(dark, p) {
+			class, fill = "dark", "#b58863"
+		}
+		fmt.Fprintf(&s, "<rect class=\"%s\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", class, left, top, squareSize, squareSize, fill)
+		if symbol := b.SymbolAt(p); symbol != "" {
+			fmt.Fprintf(&s, "<text x=\"%d\" y=\"%d\" font-size=\"%d\" text-anchor=\"middle\" dominant-baseline=\"central\">%s</text>\n", left+squareSize/2, top+squareSize/2, squareSize*3/4, html.EscapeString(symbol))
+		}
+	}
+	s.WriteString("</svg>\n")
+	return s.String()
+}
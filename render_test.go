@@ -0,0 +1,60 @@
+package bitboard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	b := NewChessBoard()
+	result := b.HTML()
+	if n := strings.Count(result, "<td "); n != 64 {
+		t.Error("Expected 64 cells, got", n)
+	}
+	if n := strings.Count(result, "<tr>"); n != 8 {
+		t.Error("Expected 8 rows, got", n)
+	}
+	for _, cell := range []string{
+		`<td class="dark" data-square="a1">R</td>`,
+		`<td class="light" data-square="h1">R</td>`,
+		`<td class="light" data-square="d1">Q</td>`,
+		`<td class="light" data-square="e4"></td>`,
+	} {
+		if !strings.Contains(result, cell) {
+			t.Error("Expected", cell)
+		}
+	}
+	// The eighth rank comes first and a1 is in the last row.
+	if strings.Index(result, `data-square="a8"`) > strings.Index(result, `data-square="a1"`) {
+		t.Error("Expected a8 before a1")
+	}
+	b.SetSymbols([]string{"<R>", "N", "B", "Q", "K", "P", "r", "n", "b", "q", "k", "p"})
+	if !strings.Contains(b.HTML(), "&lt;R&gt;</td>") {
+		t.Error("Expected symbols to be escaped")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	b := NewConnectFourBoard()
+	b.PlacePieceAlgebraic(0, "a1")
+	b.PlacePieceAlgebraic(1, "g6")
+	result := b.SVG(10)
+	if !strings.Contains(result, `viewBox="0 0 70 60"`) {
+		t.Error("Expected a 70x60 viewBox")
+	}
+	if n := strings.Count(result, "<rect "); n != 42 {
+		t.Error("Expected 42 squares, got", n)
+	}
+	if n := strings.Count(result, "<text "); n != 2 {
+		t.Error("Expected 2 symbols, got", n)
+	}
+	// a1 is drawn in the bottom-left square and g6 in the top-right.
+	for _, node := range []string{
+		`<text x="5" y="55" font-size="7" text-anchor="middle" dominant-baseline="central">R</text>`,
+		`<text x="65" y="5" font-size="7" text-anchor="middle" dominant-baseline="central">Y</text>`,
+	} {
+		if !strings.Contains(result, node) {
+			t.Error("Expected", node)
+		}
+	}
+}
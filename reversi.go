@@ -0,0 +1,67 @@
+package bitboard
+
+// Helpers for Reversi and Othello. These assume the bitmap ordering used by
+// NewReversiBoard and NewOthelloBoard: player 0 is Black and player 1 is White.
+
+// ReversiMoves returns a bitmap of the squares where a Reversi player may
+// place a disc. While any of the four central squares is empty, as at the
+// start of a game of Reversi, only those squares may be played.
+func (b *Bitboard) ReversiMoves(player int) uint64 {
+	centre := b.reversiCentre() &^ b.Occupied
+	if centre != 0 {
+		return centre
+	}
+	var moves uint64
+	for empty := b.Empty(); empty != 0; empty &= empty - 1 {
+		p := LSB(empty)
+		if b.reversiFlips(p, player) != 0 {
+			SetBit(&moves, p)
+		}
+	}
+	return moves
+}
+
+// ReversiGameOver reports whether neither Reversi player has a legal move.
+func (b *Bitboard) ReversiGameOver() bool {
+	return b.ReversiMoves(0) == 0 && b.ReversiMoves(1) == 0
+}
+
+// ReversiScore returns the number of discs held by each Reversi player.
+func (b *Bitboard) ReversiScore() (black int, white int) {
+	return PopCount(b.Bitmaps[0]), PopCount(b.Bitmaps[1])
+}
+
+// reversiCentre returns a bitmap of the four central squares of the board.
+func (b *Bitboard) reversiCentre() uint64 {
+	var mask uint64
+	for _, x := range []int{b.Files/2 - 1, b.Files / 2} {
+		for _, y := range []int{b.Ranks/2 - 1, b.Ranks / 2} {
+			SetBit(&mask, CartesianToBit(x, y, b.Files))
+		}
+	}
+	return mask
+}
+
+// reversiFlips returns a bitmap of the opponent's discs that would be flipped
+// by a Reversi player placing a disc on square p.
+func (b *Bitboard) reversiFlips(p int, player int) uint64 {
+	var flips uint64
+	own := b.Bitmaps[player]
+	opponent := b.Bitmaps[player^1]
+	x, y := BitToCartesian(p, b.Files)
+	for _, d := range kingOffsets {
+		var line uint64
+		for i, j := x+d[0], y+d[1]; i >= 0 && i < b.Files && j >= 0 && j < b.Ranks; i, j = i+d[0], j+d[1] {
+			q := CartesianToBit(i, j, b.Files)
+			if IsBitSet(own, q) {
+				flips |= line
+				break
+			}
+			if !IsBitSet(opponent, q) {
+				break
+			}
+			SetBit(&line, q)
+		}
+	}
+	return flips
+}
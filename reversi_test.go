@@ -0,0 +1,48 @@
+package bitboard
+
+import "testing"
+
+func TestReversiMoves(t *testing.T) {
+	// Black may outflank White's discs on e4 and d5 from e3, f4, c5, or d6.
+	b := NewOthelloBoard()
+	expected := uint64(1<<20 | 1<<29 | 1<<34 | 1<<43)
+	if result := b.ReversiMoves(0); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+	// Reversi opens by filling the four central squares.
+	b = NewReversiBoard()
+	expected = uint64(0x0000001818000000)
+	if result := b.ReversiMoves(1); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestReversiGameOver(t *testing.T) {
+	if NewOthelloBoard().ReversiGameOver() {
+		t.Error("Expected game in progress")
+	}
+	if NewReversiBoard().ReversiGameOver() {
+		t.Error("Expected game in progress")
+	}
+	// A filled board.
+	b := NewOthelloBoard()
+	b.Bitmaps[0] = 0xffffffffff000000
+	b.Bitmaps[1] = 0x0000000000ffffff
+	b.RecomputeOccupied()
+	if !b.ReversiGameOver() {
+		t.Error("Expected game over")
+	}
+	if black, white := b.ReversiScore(); black != 40 || white != 24 {
+		t.Error("Expected 40 and 24, got", black, "and", white)
+	}
+	// Black has captured every White disc, leaving neither player a move.
+	b = NewOthelloBoard()
+	b.Bitmaps[0] = b.Occupied
+	b.Bitmaps[1] = 0
+	if !b.ReversiGameOver() {
+		t.Error("Expected game over")
+	}
+	if black, white := b.ReversiScore(); black != 4 || white != 0 {
+		t.Error("Expected 4 and 0, got", black, "and", white)
+	}
+}
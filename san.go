@@ -0,0 +1,172 @@
+package bitboard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSAN resolves a move in Standard Algebraic Notation (e.g., "Nf3",
+// "exd5", "O-O") against the current position. It returns the origin and
+// destination bit positions and the index of the moving piece's bitmap. Use
+// ParseSANMove to learn which piece a pawn promotes to.
+func (b *Bitboard) ParseSAN(san string, sideToMove int) (from, to, m int, err error) {
+	move, err := b.ParseSANMove(san, sideToMove)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return move.From, move.To, move.Piece, nil
+}
+
+// ParseSANMove resolves a move in Standard Algebraic Notation against the
+// current position like ParseSAN, but returns the whole Move. A pawn reaching
+// the last rank must name its promotion, as in "e8=Q".
+func (b *Bitboard) ParseSANMove(san string, sideToMove int) (Move, error) {
+	s := strings.TrimRight(san, "+#!?")
+	switch s {
+	case "O-O", "0-0":
+		return b.parseCastlingSAN(san, sideToMove, true)
+	case "O-O-O", "0-0-0":
+		return b.parseCastlingSAN(san, sideToMove, false)
+	}
+	promotion := NoPromotion
+	if i := strings.IndexByte(s, '='); i != -1 {
+		if i != len(s)-2 || strings.IndexByte("RNBQ", s[i+1]) == -1 {
+			return Move{}, fmt.Errorf("bitboard: invalid SAN %q", san)
+		}
+		promotion = strings.IndexByte("RNBQ", s[i+1]) + 1
+		s = s[:i]
+	}
+	piece := Pawn
+	if len(s) > 0 && strings.IndexByte("RNBQK", s[0]) != -1 {
+		piece = strings.IndexByte("RNBQK", s[0])
+		s = s[1:]
+	}
+	if len(s) < 2 {
+		return Move{}, fmt.Errorf("bitboard: invalid SAN %q", san)
+	}
+	to, err := b.ParseAlgebraic(s[len(s)-2:])
+	if err != nil {
+		return Move{}, fmt.Errorf("bitboard: invalid SAN %q", san)
+	}
+	// Whatever remains is an optional capture marker and disambiguation.
+	s = strings.Replace(s[:len(s)-2], "x", "", 1)
+	if len(s) > 2 {
+		return Move{}, fmt.Errorf("bitboard: invalid SAN %q", san)
+	}
+	file, rank := -1, -1
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'h':
+			file = int(c - 'a')
+		case c >= '1' && c <= '8':
+			rank = int(c - '1')
+		default:
+			return Move{}, fmt.Errorf("bitboard: invalid SAN %q", san)
+		}
+	}
+	m := sideToMove*6 + piece
+	var match Move
+	found := false
+	for _, move := range b.LegalMoves(sideToMove) {
+		if move.Piece != m || move.To != to || move.Promotion != promotion {
+			continue
+		}
+		x, y := BitToCartesian(move.From, 8)
+		if (file != -1 && x != file) || (rank != -1 && y != rank) {
+			continue
+		}
+		if found {
+			return Move{}, fmt.Errorf("bitboard: ambiguous SAN %q", san)
+		}
+		match, found = move, true
+	}
+	if !found {
+		return Move{}, fmt.Errorf("bitboard: no legal move matches SAN %q", san)
+	}
+	return match, nil
+}
+
+// SAN returns move m, which must be legal in the current position, in Standard
+// Algebraic Notation (e.g., "Nf3", "exd5", "O-O", "e8=Q+"). The moving piece's
+// origin is only given where another piece of the same kind could also reach
+// the destination.
+func (b *Bitboard) SAN(m Move) string {
+	player, piece := m.Piece/6, m.Piece%6
+	var s string
+	switch {
+	case piece == King && m.To-m.From == 2:
+		s = "O-O"
+	case piece == King && m.From-m.To == 2:
+		s = "O-O-O"
+	default:
+		// A pawn that changes file captures, even en passant.
+		capture := IsBitSet(b.Occupied, m.To) || (piece == Pawn && m.From%8 != m.To%8)
+		if piece != Pawn {
+			s = string("RNBQK"[piece]) + b.disambiguateSAN(m)
+		} else if capture {
+			s = BitToAlgebraic(m.From, 8)[:1]
+		}
+		if capture {
+			s += "x"
+		}
+		s += BitToAlgebraic(m.To, 8)
+		if m.Promotion != NoPromotion {
+			s += "=" + string("RNBQ"[m.Promotion-1])
+		}
+	}
+	u := b.MakeMove(m)
+	switch {
+	case b.IsCheckmate(player ^ 1):
+		s += "#"
+	case b.InCheck(player ^ 1):
+		s += "+"
+	}
+	b.UnmakeMove(m, u)
+	return s
+}
+
+// disambiguateSAN returns the file, rank, or square of move m's origin needed
+// to tell it apart from other legal moves of the same kind of piece to the
+// same destination, or an empty string if there are none.
+func (b *Bitboard) disambiguateSAN(m Move) string {
+	ambiguous, sameFile, sameRank := false, false, false
+	x, y := BitToCartesian(m.From, 8)
+	for _, move := range b.LegalMoves(m.Piece / 6) {
+		if move.Piece != m.Piece || move.To != m.To || move.From == m.From {
+			continue
+		}
+		ambiguous = true
+		i, j := BitToCartesian(move.From, 8)
+		sameFile = sameFile || i == x
+		sameRank = sameRank || j == y
+	}
+	from := BitToAlgebraic(m.From, 8)
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return from[:1]
+	case !sameRank:
+		return from[1:]
+	}
+	return from
+}
+
+// parseCastlingSAN resolves a castling move to the king's move, provided
+// castling that way is legal.
+func (b *Bitboard) parseCastlingSAN(san string, player int, kingside bool) (Move, error) {
+	from, m := 4, player*6+King
+	if player == Black {
+		from = 60
+	}
+	to := from + 2
+	if !kingside {
+		to = from - 2
+	}
+	for _, move := range b.LegalMoves(player) {
+		if move.Piece == m && move.From == from && move.To == to {
+			return move, nil
+		}
+	}
+	return Move{}, fmt.Errorf("bitboard: no legal move matches SAN %q", san)
+}
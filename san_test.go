@@ -0,0 +1,137 @@
+package bitboard
+
+import "testing"
+
+func TestParseSAN(t *testing.T) {
+	b, err := ParseFEN("r3k2r/pp3ppp/8/3p4/4P3/5N2/PPP2PPP/RN2K2R w KQkq -")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	tests := []struct {
+		san      string
+		player   int
+		from, to int
+		m        int
+	}{
+		{"a4", White, 8, 24, WhitePawns},
+		{"exd5", White, 28, 35, WhitePawns},
+		{"Nbd2", White, 1, 11, WhiteKnights},
+		{"Nfd2", White, 21, 11, WhiteKnights},
+		{"N3d2", White, 21, 11, WhiteKnights},
+		{"Ng5+", White, 21, 38, WhiteKnights},
+		{"O-O", White, 4, 6, WhiteKing},
+		{"O-O-O", Black, 60, 58, BlackKing},
+		{"dxe4", Black, 35, 28, BlackPawns},
+	}
+	for _, test := range tests {
+		from, to, m, err := b.ParseSAN(test.san, test.player)
+		if err != nil {
+			t.Error("Expected no error for", test.san, ", got", err)
+			continue
+		}
+		if from != test.from || to != test.to || m != test.m {
+			t.Error("Expected", test.from, test.to, test.m, ", got", from, to, m)
+		}
+	}
+}
+
+func TestSAN(t *testing.T) {
+	b, err := ParseFEN("r3k2r/pp3ppp/8/3p4/4P3/5N2/PPP2PPP/RN2K2R w KQkq -")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	tests := []struct {
+		m        Move
+		expected string
+	}{
+		{Move{From: 8, To: 24, Piece: WhitePawns}, "a4"},
+		{Move{From: 28, To: 35, Piece: WhitePawns}, "exd5"},
+		{Move{From: 1, To: 11, Piece: WhiteKnights}, "Nbd2"},
+		{Move{From: 21, To: 38, Piece: WhiteKnights}, "Ng5"},
+		{Move{From: 4, To: 6, Piece: WhiteKing}, "O-O"},
+		{Move{From: 7, To: 5, Piece: WhiteRooks}, "Rf1"},
+	}
+	for _, test := range tests {
+		if result := b.SAN(test.m); result != test.expected {
+			t.Error("Expected", test.expected, ", got", result)
+		}
+	}
+	// Rooks on the same file are told apart by rank.
+	b, _ = ParseFEN("4k3/8/8/R7/8/8/8/R3K3 w")
+	if result := b.SAN(Move{From: 0, To: 16, Piece: WhiteRooks}); result != "R1a3" {
+		t.Error("Expected R1a3, got", result)
+	}
+	// Checks, mates, and promotions.
+	b, _ = ParseFEN("6k1/4P3/8/8/8/8/5PPP/3R2K1 w")
+	if result := b.SAN(Move{From: 3, To: 59, Piece: WhiteRooks}); result != "Rd8+" {
+		t.Error("Expected Rd8+, got", result)
+	}
+	if result := b.SAN(Move{From: 52, To: 60, Piece: WhitePawns, Promotion: PromoteQueen}); result != "e8=Q+" {
+		t.Error("Expected e8=Q+, got", result)
+	}
+	b, _ = ParseFEN("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w")
+	if result := b.SAN(Move{From: 3, To: 59, Piece: WhiteRooks}); result != "Rd8#" {
+		t.Error("Expected Rd8#, got", result)
+	}
+	// SAN agrees with ParseSAN.
+	b = NewChessBoard()
+	for _, m := range b.LegalMoves(White) {
+		from, to, piece, err := b.ParseSAN(b.SAN(m), White)
+		if err != nil || from != m.From || to != m.To || piece != m.Piece {
+			t.Error("Expected", m, "to round trip, got", from, to, piece, err)
+		}
+	}
+	// Promotions round trip through ParseSANMove.
+	b, _ = ParseFEN("3qk3/2P5/8/8/8/8/8/4K3 w - -")
+	for _, m := range b.LegalMoves(White) {
+		if result, err := b.ParseSANMove(b.SAN(m), White); err != nil || result != m {
+			t.Error("Expected", m, "to round trip, got", result, err)
+		}
+	}
+}
+
+func TestParseSANMovePromotion(t *testing.T) {
+	b, err := ParseFEN("3qk3/2P5/8/8/8/8/8/4K3 w - -")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	tests := []struct {
+		san      string
+		expected Move
+	}{
+		{"c8=Q+", Move{From: 50, To: 58, Piece: WhitePawns, Promotion: PromoteQueen}},
+		{"c8=N", Move{From: 50, To: 58, Piece: WhitePawns, Promotion: PromoteKnight}},
+		{"cxd8=R+", Move{From: 50, To: 59, Piece: WhitePawns, Promotion: PromoteRook}},
+	}
+	for _, test := range tests {
+		if result, err := b.ParseSANMove(test.san, White); err != nil || result != test.expected {
+			t.Error("Expected", test.expected, ", got", result, err)
+		}
+	}
+	// A pawn reaching the last rank must say what it promotes to.
+	for _, san := range []string{"c8", "c8=K", "c8=", "c8=QQ", "Kd2=Q"} {
+		if _, err := b.ParseSANMove(san, White); err == nil {
+			t.Error("Expected error for", san)
+		}
+	}
+}
+
+func TestParseSANInvalid(t *testing.T) {
+	b, err := ParseFEN("r3k2r/pp3ppp/8/3p4/4P3/5N2/PPP2PPP/RN2K2R w")
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	// Without castling rights, White may not castle either way.
+	for _, san := range []string{"", "N", "Nd2", "Nz9", "Nh5", "Qd4", "O-O", "O-O-O", "e6", "Nbcd2"} {
+		if _, _, _, err := b.ParseSAN(san, White); err == nil {
+			t.Error("Expected error for", san)
+		}
+	}
+	// Nor may a king in check castle out of it.
+	b, _ = ParseFEN("4k3/8/8/8/8/8/4r3/R3K2R w KQ -")
+	for _, san := range []string{"O-O", "O-O-O"} {
+		if _, _, _, err := b.ParseSAN(san, White); err == nil {
+			t.Error("Expected error for", san)
+		}
+	}
+}
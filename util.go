@@ -3,7 +3,9 @@ package bitboard
 
 import (
 	"fmt"
+	"math/bits"
 	"strconv"
+	"strings"
 )
 
 //-----------------------------------------------------------------------------
@@ -51,6 +53,11 @@ func Union(i ...uint64) uint64 {
 	return u
 }
 
+// UnionSlice calculates the union of a slice of integers.
+func UnionSlice(i []uint64) uint64 {
+	return Union(i...)
+}
+
 // PopCount calculates the population count (Hamming weight) of an integer
 // using a divide-and-conquer approach.
 //
@@ -70,6 +77,206 @@ func PopCount(i uint64) int {
 	return int(i & 0x7f)
 }
 
+// LSB returns the position of the least significant set bit, or -1 if no bits
+// are set.
+func LSB(i uint64) int {
+	if i == 0 {
+		return -1
+	}
+	return bits.TrailingZeros64(i)
+}
+
+// Bits returns the positions of the set bits in ascending order.
+func Bits(i uint64) []int {
+	positions := make([]int, 0, PopCount(i))
+	for ; i != 0; i &= i - 1 {
+		positions = append(positions, LSB(i))
+	}
+	return positions
+}
+
+// PrintBitmap renders a bitmap as a grid with the given number of files and
+// ranks, using the same mapping as a Bitboard. Set bits are shown as 1s and
+// clear bits as periods, with the highest rank first.
+func PrintBitmap(i uint64, files int, ranks int) string {
+	var s strings.Builder
+	for y := ranks - 1; y >= 0; y-- {
+		for x := 0; x < files; x++ {
+			if IsBitSet(i, CartesianToBit(x, y, files)) {
+				s.WriteString("1")
+			} else {
+				s.WriteString(".")
+			}
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+//-----------------------------------------------------------------------------
+// Shifting and filling
+//-----------------------------------------------------------------------------
+
+// fileMask returns a bitmap of every square on file x of a board with the
+// given number of files, extended across all 64 bits. A board with no files
+// has no squares on any file.
+func fileMask(x int, files int) uint64 {
+	var mask uint64
+	if files <= 0 {
+		return mask
+	}
+	for p := x; p < 64; p += files {
+		SetBit(&mask, p)
+	}
+	return mask
+}
+
+// DarkSquares returns a bitmap of the dark squares of a checkered board with
+// the given number of files and ranks. The square a1 is dark.
+func DarkSquares(files int, ranks int) uint64 {
+	var mask uint64
+	for y := 0; y < ranks; y++ {
+		for x := y % 2; x < files; x += 2 {
+			SetBit(&mask, CartesianToBit(x, y, files))
+		}
+	}
+	return mask
+}
+
+// LightSquares returns a bitmap of the light squares of a checkered board
+// with the given number of files and ranks.
+func LightSquares(files int, ranks int) uint64 {
+	board := uint64(1)<<uint(files*ranks) - 1
+	return board &^ DarkSquares(files, ranks)
+}
+
+// fileRange returns a bitmap of every square on files lo through hi-1 of a
+// board with the given number of files, extended across all 64 bits.
+func fileRange(lo int, hi int, files int) uint64 {
+	var mask uint64
+	for x := lo; x < hi; x++ {
+		mask |= fileMask(x, files)
+	}
+	return mask
+}
+
+// shift moves every set bit of i by (dx, dy) on a board with the given number
+// of files and ranks. Bits pushed past an edge are dropped, or reappear at the
+// opposite edge if wrap is true.
+func shift(i uint64, dx int, dy int, files int, ranks int, wrap bool) uint64 {
+	board := uint64(1)<<uint(files*ranks) - 1
+	i &= board
+	if wrap {
+		dx = (dx%files + files) % files
+		dy = (dy%ranks + ranks) % ranks
+	}
+	if dx <= -files || dx >= files || dy <= -ranks || dy >= ranks {
+		return 0
+	}
+	switch {
+	case dx > 0:
+		stay := fileRange(0, files-dx, files)
+		moved := (i & stay) << uint(dx)
+		if wrap {
+			moved |= (i &^ stay) >> uint(files-dx)
+		}
+		i = moved
+	case dx < 0:
+		i = (i & fileRange(-dx, files, files)) >> uint(-dx)
+	}
+	switch {
+	case dy > 0:
+		moved := (i << uint(dy*files)) & board
+		if wrap {
+			moved |= i >> uint((ranks-dy)*files)
+		}
+		i = moved
+	case dy < 0:
+		i >>= uint(-dy * files)
+	}
+	return i
+}
+
+// orthogonalShifts returns the squares orthogonally adjacent to any square in
+// i, without wrapping around the edges of a board with the given number of
+// files.
+func orthogonalShifts(i uint64, files int) uint64 {
+	north := i << uint(files)
+	south := i >> uint(files)
+	east := (i << 1) &^ fileMask(0, files)
+	west := (i >> 1) &^ fileMask(files-1, files)
+	return north | south | east | west
+}
+
+// NorthFill smears every set bit of an 8x8 bitmap towards the eighth rank.
+func NorthFill(i uint64) uint64 {
+	i |= i << 8
+	i |= i << 16
+	i |= i << 32
+	return i
+}
+
+// SouthFill smears every set bit of an 8x8 bitmap towards the first rank.
+func SouthFill(i uint64) uint64 {
+	i |= i >> 8
+	i |= i >> 16
+	i |= i >> 32
+	return i
+}
+
+// FileFill smears every set bit of an 8x8 bitmap along its whole file.
+func FileFill(i uint64) uint64 {
+	return NorthFill(i) | SouthFill(i)
+}
+
+// RankFill smears every set bit of an 8x8 bitmap along its whole rank.
+func RankFill(i uint64) uint64 {
+	east, west := i, i
+	notA, notH := ^fileMask(0, 8), ^fileMask(7, 8)
+	for _, n := range []uint{1, 2, 4} {
+		east |= notA & (east << n)
+		west |= notH & (west >> n)
+		notA &= notA << n
+		notH &= notH >> n
+	}
+	return east | west
+}
+
+// FloodFill expands seeds through orthogonally adjacent squares in mask
+// until the region stops growing, and returns the region reached. This finds
+// connected groups of pieces, such as groups of stones in Go.
+func FloodFill(seeds uint64, mask uint64, files int) uint64 {
+	region := seeds & mask
+	for {
+		grown := (region | orthogonalShifts(region, files)) & mask
+		if grown == region {
+			return region
+		}
+		region = grown
+	}
+}
+
+// Dilate grows mask by the squares a king could reach from it in up to steps
+// moves, without leaving a board with the given number of files and ranks.
+func Dilate(mask uint64, steps int, files int, ranks int) uint64 {
+	board := uint64(1)<<uint(files*ranks) - 1
+	mask &= board
+	for i := 0; i < steps; i++ {
+		mask |= (mask<<1)&^fileMask(0, files) | (mask>>1)&^fileMask(files-1, files)
+		mask |= (mask<<uint(files) | mask>>uint(files)) & board
+	}
+	return mask
+}
+
+// Erode shrinks mask by removing every square within steps king moves of a
+// square outside it, on a board with the given number of files and ranks.
+// Squares off every edge of the board count as inside the mask, so a full
+// board does not erode.
+func Erode(mask uint64, steps int, files int, ranks int) uint64 {
+	board := uint64(1)<<uint(files*ranks) - 1
+	return board &^ Dilate(board&^mask, steps, files, ranks)
+}
+
 //-----------------------------------------------------------------------------
 // Flipping and rotating
 //-----------------------------------------------------------------------------
@@ -113,6 +320,7 @@ func FlipDiagonalA1H8(i uint64) uint64 {
 	return i
 }
 
+// Flip a bitboard about the diagonal A8-H1.
 func FlipDiagonalA8H1(i uint64) uint64 {
 	var t uint64
 	k1 := uint64(0xaa00aa00aa00aa00)
@@ -147,7 +355,9 @@ func Rotate270(i uint64) uint64 {
 //-----------------------------------------------------------------------------
 
 // Convert coordinates in algebraic notation to Cartesian coordinates.
+// Surrounding whitespace and the case of the file letter are ignored.
 func AlgebraicToCartesian(p string, files int) (int, int) {
+	p = strings.ToLower(strings.TrimSpace(p))
 	symbols := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
 	var x int
 	for i, v := range symbols {
@@ -189,3 +399,235 @@ func CartesianToBit(x int, y int, files int) int {
 	bit := y*files + x
 	return bit
 }
+
+// ParseAlgebraic converts coordinates in algebraic notation to an integer bit
+// position, returning an error if they are malformed or fall outside a board
+// with the given number of files and ranks. Surrounding whitespace and the
+// case of the file letter are ignored.
+func ParseAlgebraic(p string, files int, ranks int) (int, error) {
+	s := strings.ToLower(strings.TrimSpace(p))
+	if len(s) < 2 || s[0] < 'a' || int(s[0]-'a') >= files {
+		return 0, fmt.Errorf("bitboard: invalid algebraic coordinates %q", p)
+	}
+	y, err := strconv.Atoi(s[1:])
+	if err != nil || s[1] < '1' || s[1] > '9' || y > ranks {
+		return 0, fmt.Errorf("bitboard: invalid algebraic coordinates %q", p)
+	}
+	return CartesianToBit(int(s[0]-'a'), y-1, files), nil
+}
+
+// Neighbors returns the bit positions of the squares adjacent to pos on a
+// board with the given number of ranks and files, in ascending order.
+// Diagonal neighbors are included if diagonal is true.
+func Neighbors(pos int, ranks int, files int, diagonal bool) []int {
+	var neighbors []int
+	x, y := BitToCartesian(pos, files)
+	for j := y - 1; j <= y+1; j++ {
+		for i := x - 1; i <= x+1; i++ {
+			if i < 0 || i >= files || j < 0 || j >= ranks || (i == x && j == y) {
+				continue
+			}
+			if !diagonal && i != x && j != y {
+				continue
+			}
+			neighbors = append(neighbors, CartesianToBit(i, j, files))
+		}
+	}
+	return neighbors
+}
+
+// ChebyshevDistance returns the number of king moves between bit positions a
+// and b on a board with the given number of files.
+func ChebyshevDistance(a int, b int, files int) int {
+	x1, y1 := BitToCartesian(a, files)
+	x2, y2 := BitToCartesian(b, files)
+	dx, dy := abs(x2-x1), abs(y2-y1)
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+// ManhattanDistance returns the number of orthogonal steps between bit
+// positions a and b on a board with the given number of files.
+func ManhattanDistance(a int, b int, files int) int {
+	x1, y1 := BitToCartesian(a, files)
+	x2, y2 := BitToCartesian(b, files)
+	return abs(x2-x1) + abs(y2-y1)
+}
+
+// Masks of the ranks, files, and diagonals of an 8x8 board, indexed by
+// Cartesian coordinate, Diagonal, and AntiDiagonal respectively. For example,
+// RankMask[0] is the first rank (a1-h1) and FileMask[0] is the a-file.
+var (
+	RankMask         [8]uint64
+	FileMask         [8]uint64
+	DiagonalMask     [15]uint64
+	AntiDiagonalMask [15]uint64
+)
+
+func init() {
+	for p := 0; p < 64; p++ {
+		x, y := BitToCartesian(p, 8)
+		SetBit(&RankMask[y], p)
+		SetBit(&FileMask[x], p)
+		SetBit(&DiagonalMask[Diagonal(p)], p)
+		SetBit(&AntiDiagonalMask[AntiDiagonal(p)], p)
+	}
+}
+
+// Diagonal returns the index, from 0 to 14, of the A1-H8 diagonal through bit
+// position pos on an 8x8 board. Diagonals are numbered from h1 (0) to a8
+// (14), so the long diagonal from a1 to h8 is 7.
+func Diagonal(pos int) int {
+	x, y := BitToCartesian(pos, 8)
+	return y - x + 7
+}
+
+// AntiDiagonal returns the index, from 0 to 14, of the A8-H1 diagonal through
+// bit position pos on an 8x8 board. Anti-diagonals are numbered from a1 (0) to
+// h8 (14), so the long diagonal from a8 to h1 is 7.
+func AntiDiagonal(pos int) int {
+	x, y := BitToCartesian(pos, 8)
+	return x + y
+}
+
+//-----------------------------------------------------------------------------
+// Lines
+//-----------------------------------------------------------------------------
+
+// LineMasks returns every straight line of n squares (horizontal, vertical,
+// and both diagonals) that fits entirely on a board with the given number of
+// ranks and files.
+func LineMasks(ranks int, files int, n int) []uint64 {
+	var masks []uint64
+	directions := [][2]int{{1, 0}, {0, 1}, {1, 1}, {-1, 1}}
+	for _, d := range directions {
+		for y := 0; y < ranks; y++ {
+			for x := 0; x < files; x++ {
+				endX, endY := x+d[0]*(n-1), y+d[1]*(n-1)
+				if n < 1 || endX < 0 || endX >= files || endY >= ranks {
+					continue
+				}
+				var mask uint64
+				for i := 0; i < n; i++ {
+					SetBit(&mask, CartesianToBit(x+d[0]*i, y+d[1]*i, files))
+				}
+				masks = append(masks, mask)
+			}
+		}
+	}
+	return masks
+}
+
+// winLines holds the winning lines of each game supported by WinLines.
+var winLines = map[string][]uint64{
+	"tictactoe":   LineMasks(3, 3, 3),
+	"connectfour": LineMasks(6, 7, 4),
+	"gomoku":      LineMasks(8, 8, 5),
+}
+
+// WinLines returns the lines a player must fill to win the named game on the
+// board built by its convenience constructor: "tictactoe", "connectfour", or
+// "gomoku".
+func WinLines(game string) ([]uint64, error) {
+	lines, ok := winLines[game]
+	if !ok {
+		return nil, fmt.Errorf("bitboard: unknown game %q", game)
+	}
+	return append([]uint64(nil), lines...), nil
+}
+
+// Between returns a bitmap of the squares strictly between bit positions a
+// and b on a board with the given number of files, or 0 if they do not share
+// a rank, file, or diagonal.
+func Between(a int, b int, files int) uint64 {
+	dx, dy, ok := direction(a, b, files)
+	if !ok {
+		return 0
+	}
+	var mask uint64
+	for p := a + dy*files + dx; p != b; p += dy*files + dx {
+		SetBit(&mask, p)
+	}
+	return mask
+}
+
+// Line returns a bitmap of the whole rank, file, or diagonal passing through
+// bit positions a and b, from edge to edge of a board with the given number of
+// files and ranks, or 0 if they do not share one.
+func Line(a int, b int, files int, ranks int) uint64 {
+	dx, dy, ok := direction(a, b, files)
+	if !ok {
+		return 0
+	}
+	var mask uint64
+	for _, d := range []int{1, -1} {
+		x, y := BitToCartesian(a, files)
+		for x >= 0 && x < files && y >= 0 && y < ranks {
+			SetBit(&mask, CartesianToBit(x, y, files))
+			x, y = x+dx*d, y+dy*d
+		}
+	}
+	return mask
+}
+
+// ShortestPath returns a shortest sequence of squares leading from square
+// from to square to, including both, where moves returns the squares that can
+// be reached in one move from a given square. It returns nil if to cannot be
+// reached. The search is breadth-first, so every move counts the same.
+func ShortestPath(from int, to int, moves func(int) []int) []int {
+	previous := map[int]int{from: from}
+	for frontier := []int{from}; len(frontier) > 0; {
+		var next []int
+		for _, p := range frontier {
+			if p == to {
+				path := []int{p}
+				for p != from {
+					p = previous[p]
+					path = append([]int{p}, path...)
+				}
+				return path
+			}
+			for _, q := range moves(p) {
+				if _, ok := previous[q]; !ok {
+					previous[q] = p
+					next = append(next, q)
+				}
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// direction returns the unit step (dx, dy) from bit position a towards b, and
+// whether the two squares are distinct and share a rank, file, or diagonal.
+func direction(a int, b int, files int) (int, int, bool) {
+	x1, y1 := BitToCartesian(a, files)
+	x2, y2 := BitToCartesian(b, files)
+	dx, dy := sign(x2-x1), sign(y2-y1)
+	if a == b || (x1 != x2 && y1 != y2 && abs(x2-x1) != abs(y2-y1)) {
+		return 0, 0, false
+	}
+	return dx, dy, true
+}
+
+// sign returns -1, 0, or 1 according to the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -1,6 +1,10 @@
 package bitboard
 
-import "testing"
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
 
 var positionsAlgebraic = []string{
 	"a1", "b1", "c1", "d1", "e1", "f1", "g1", "h1",
@@ -31,6 +35,12 @@ func TestAlgebraicToBit(t *testing.T) {
 			t.Error("Expected", positionsBit[i], ", got", result)
 		}
 	}
+	// Case and surrounding whitespace are ignored.
+	for _, p := range []string{"E4", "e4", " E4 "} {
+		if result := AlgebraicToBit(p, 8); result != 28 {
+			t.Error("Expected 28 for", p, ", got", result)
+		}
+	}
 }
 
 func TestAlgebraicToCartesian(t *testing.T) {
@@ -89,3 +99,558 @@ func TestCartesianToBit(t *testing.T) {
 		}
 	}
 }
+
+func TestUnionSlice(t *testing.T) {
+	i := []uint64{0x00000000000000ff, 0x000000000000ff00, 0x0000000000000101}
+	result := UnionSlice(i)
+	if result != 0x000000000000ffff {
+		t.Errorf("Expected %#x, got %#x", 0x000000000000ffff, result)
+	}
+	if UnionSlice(nil) != 0 {
+		t.Error("Expected union of empty slice to be 0")
+	}
+}
+
+func TestLSB(t *testing.T) {
+	expected := map[uint64]int{
+		0x0000000000000000: -1,
+		0x0000000000000001: 0,
+		0x0000000000000110: 4,
+		0x8000000000000000: 63,
+	}
+	for i, p := range expected {
+		result := LSB(i)
+		if result != p {
+			t.Error("Expected", p, ", got", result)
+		}
+	}
+}
+
+func TestBits(t *testing.T) {
+	if result := Bits(0); len(result) != 0 {
+		t.Error("Expected no bits, got", result)
+	}
+	if result := Bits(0x0000000000000100); !reflect.DeepEqual(result, []int{8}) {
+		t.Error("Expected [8], got", result)
+	}
+	result := Bits(0xffffffffffffffff)
+	if len(result) != 64 {
+		t.Fatal("Expected 64 bits, got", len(result))
+	}
+	for i, p := range result {
+		if p != i {
+			t.Error("Expected", i, ", got", p)
+		}
+	}
+}
+
+func TestParseAlgebraic(t *testing.T) {
+	for i, p := range positionsAlgebraic {
+		result, err := ParseAlgebraic(p, 8, 8)
+		if err != nil {
+			t.Error("Expected no error, got", err)
+		}
+		if result != positionsBit[i] {
+			t.Error("Expected", positionsBit[i], ", got", result)
+		}
+	}
+	for _, p := range []string{"E4", " e4", "e4 ", "\tE4\n"} {
+		result, err := ParseAlgebraic(p, 8, 8)
+		if err != nil {
+			t.Error("Expected no error, got", err)
+		}
+		if result != 28 {
+			t.Error("Expected 28, got", result)
+		}
+	}
+	for _, p := range []string{"", " ", "e", "i1", "I1", "a0", "a9", "a+1", "a-1", "e 4", "4e", "c4"} {
+		if _, err := ParseAlgebraic(p, 3, 3); err == nil {
+			t.Error("Expected error for", p)
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	expected := []struct {
+		pos          int
+		ranks, files int
+		diagonal     bool
+		neighbors    []int
+	}{
+		{0, 8, 8, true, []int{1, 8, 9}},
+		{0, 8, 8, false, []int{1, 8}},
+		{63, 8, 8, true, []int{54, 55, 62}},
+		{28, 8, 8, true, []int{19, 20, 21, 27, 29, 35, 36, 37}},
+		{28, 8, 8, false, []int{20, 27, 29, 36}},
+		{4, 3, 3, false, []int{1, 3, 5, 7}},
+		{6, 6, 7, true, []int{5, 12, 13}}, // g1 on a Connect Four board
+	}
+	for _, e := range expected {
+		result := Neighbors(e.pos, e.ranks, e.files, e.diagonal)
+		if !reflect.DeepEqual(result, e.neighbors) {
+			t.Error("Expected", e.neighbors, ", got", result)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	expected := []struct {
+		a, b, files          int
+		chebyshev, manhattan int
+	}{
+		{28, 28, 8, 0, 0},
+		{28, 29, 8, 1, 1},
+		{28, 37, 8, 1, 2},
+		{0, 63, 8, 7, 14},
+		{7, 56, 8, 7, 14},
+		{7, 8, 8, 7, 8}, // h1 and a2 are not adjacent
+		{0, 8, 3, 2, 4},
+	}
+	for _, e := range expected {
+		if result := ChebyshevDistance(e.a, e.b, e.files); result != e.chebyshev {
+			t.Error("Expected", e.chebyshev, ", got", result)
+		}
+		if result := ManhattanDistance(e.a, e.b, e.files); result != e.manhattan {
+			t.Error("Expected", e.manhattan, ", got", result)
+		}
+	}
+}
+
+func TestDiagonal(t *testing.T) {
+	var tests = []struct {
+		p            string
+		diagonal     int
+		antiDiagonal int
+	}{
+		{"a1", 7, 0},
+		{"h8", 7, 14},
+		{"d4", 7, 6},
+		{"h1", 0, 7},
+		{"a8", 14, 7},
+		{"e4", 6, 7},
+		{"b1", 6, 1},
+	}
+	for _, test := range tests {
+		p := AlgebraicToBit(test.p, 8)
+		if result := Diagonal(p); result != test.diagonal {
+			t.Error("Expected", test.diagonal, "for", test.p, ", got", result)
+		}
+		if result := AntiDiagonal(p); result != test.antiDiagonal {
+			t.Error("Expected", test.antiDiagonal, "for", test.p, ", got", result)
+		}
+	}
+}
+
+func TestMaskTables(t *testing.T) {
+	if RankMask[0] != 0xff {
+		t.Errorf("Expected %#016x, got %#016x", 0xff, RankMask[0])
+	}
+	if RankMask[7] != 0xff00000000000000 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0xff00000000000000), RankMask[7])
+	}
+	if FileMask[0] != 0x0101010101010101 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0101010101010101, FileMask[0])
+	}
+	if FileMask[7] != 0x8080808080808080 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0x8080808080808080), FileMask[7])
+	}
+	if DiagonalMask[7] != 0x8040201008040201 {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0x8040201008040201), DiagonalMask[7])
+	}
+	if AntiDiagonalMask[7] != 0x0102040810204080 {
+		t.Errorf("Expected %#016x, got %#016x", 0x0102040810204080, AntiDiagonalMask[7])
+	}
+	if DiagonalMask[0] != 1<<7 || DiagonalMask[14] != 1<<56 || AntiDiagonalMask[0] != 1 || AntiDiagonalMask[14] != 1<<63 {
+		t.Error("Expected corner diagonals to hold a single square")
+	}
+	// Every square lies on exactly one of each kind of line.
+	for _, masks := range [][]uint64{RankMask[:], FileMask[:], DiagonalMask[:], AntiDiagonalMask[:]} {
+		if Union(masks...) != 0xffffffffffffffff {
+			t.Error("Expected masks to cover the board")
+		}
+		total := 0
+		for _, m := range masks {
+			total += PopCount(m)
+		}
+		if total != 64 {
+			t.Error("Expected 64 squares, got", total)
+		}
+	}
+	// The diagonal masks agree with the bishop's attacks on an empty board.
+	d4 := AlgebraicToBit("d4", 8)
+	expected := (DiagonalMask[Diagonal(d4)] | AntiDiagonalMask[AntiDiagonal(d4)]) &^ (1 << uint(d4))
+	if result := BishopAttacks(d4, 0); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	knight := func(p int) []int { return Bits(KnightAttacks(p)) }
+	a1, h8 := AlgebraicToBit("a1", 8), AlgebraicToBit("h8", 8)
+	path := ShortestPath(a1, h8, knight)
+	if len(path) != 7 || path[0] != a1 || path[6] != h8 {
+		t.Fatal("Expected a path of 6 knight moves from a1 to h8, got", path)
+	}
+	for i := 1; i < len(path); i++ {
+		if !IsBitSet(KnightAttacks(path[i-1]), path[i]) {
+			t.Error("Expected a knight move from", path[i-1], "to", path[i])
+		}
+	}
+	if result := ShortestPath(a1, a1, knight); !reflect.DeepEqual(result, []int{a1}) {
+		t.Error("Expected", []int{a1}, ", got", result)
+	}
+	// A knight confined to the corner cannot escape it.
+	corner := func(p int) []int { return Bits(KnightAttacks(p) & 0x0000000000070707) }
+	if result := ShortestPath(a1, h8, corner); result != nil {
+		t.Error("Expected nil, got", result)
+	}
+	// The path length agrees with KnightDistance.
+	for _, to := range []int{1, 9, 27, 62} {
+		if n := len(ShortestPath(a1, to, knight)) - 1; n != KnightDistance(a1, to) {
+			t.Error("Expected", KnightDistance(a1, to), "moves, got", n)
+		}
+	}
+}
+
+func TestLineMasks(t *testing.T) {
+	expected := []struct {
+		ranks, files, n int
+		count           int
+	}{
+		{3, 3, 3, 8},  // Tic-Tac-Toe
+		{6, 7, 4, 69}, // Connect Four
+		{8, 8, 5, 96}, // Gomoku on an 8x8 board
+		{3, 3, 4, 0},
+	}
+	for _, e := range expected {
+		result := len(LineMasks(e.ranks, e.files, e.n))
+		if result != e.count {
+			t.Error("Expected", e.count, ", got", result)
+		}
+	}
+	masks := map[uint64]bool{}
+	for _, m := range LineMasks(3, 3, 3) {
+		masks[m] = true
+	}
+	// Bottom row, middle column, and both diagonals.
+	for _, m := range []uint64{0x007, 0x092, 0x111, 0x054} {
+		if !masks[m] {
+			t.Errorf("Expected line %#03x", m)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	expected := []struct {
+		a, b int
+		mask uint64
+	}{
+		{0, 7, 0x000000000000007e},  // a1-h1
+		{60, 4, 0x0010101010101000}, // e8-e1
+		{0, 63, 0x0040201008040200}, // a1-h8
+		{7, 56, 0x0002040810204000}, // h1-a8
+		{0, 1, 0},                   // adjacent
+		{0, 0, 0},
+		{0, 17, 0}, // a1-b3 is not aligned
+		{7, 8, 0},  // h1-a2 is not aligned
+	}
+	for _, e := range expected {
+		if result := Between(e.a, e.b, 8); result != e.mask {
+			t.Errorf("Expected %#016x, got %#016x", e.mask, result)
+		}
+	}
+	// The middle of a Tic-Tac-Toe diagonal.
+	if result := Between(2, 6, 3); result != 0x010 {
+		t.Errorf("Expected %#03x, got %#03x", 0x010, result)
+	}
+}
+
+func TestLine(t *testing.T) {
+	expected := []struct {
+		a, b int
+		mask uint64
+	}{
+		{0, 7, 0x00000000000000ff},   // a1-h1
+		{12, 52, 0x1010101010101010}, // e2-e7
+		{9, 18, 0x8040201008040201},  // b2-c3 runs from a1 to h8
+		{21, 42, 0x0102040810204080}, // f3-c6 runs from h1 to a8
+		{16, 25, 0x2010080402010000}, // a3-b4 runs to f8
+		{0, 0, 0},
+		{0, 17, 0},
+	}
+	for _, e := range expected {
+		if result := Line(e.a, e.b, 8, 8); result != e.mask {
+			t.Errorf("Expected %#016x, got %#016x", e.mask, result)
+		}
+		if e.mask != 0 && (!IsBitSet(e.mask, e.a) || !IsBitSet(e.mask, e.b)) {
+			t.Error("Expected line to include", e.a, "and", e.b)
+		}
+	}
+	// Lines end at the edges of smaller boards.
+	smaller := []struct {
+		a, b         int
+		files, ranks int
+		mask         uint64
+	}{
+		{0, 3, 3, 3, 0x049},       // a1-a2 on a 3x3 board
+		{0, 4, 3, 3, 0x111},       // a1-b2 on a 3x3 board
+		{2, 4, 3, 3, 0x054},       // c1-b2 on a 3x3 board
+		{0, 7, 7, 6, 0x810204081}, // a1-a2 on a 7x6 board
+	}
+	for _, e := range smaller {
+		if result := Line(e.a, e.b, e.files, e.ranks); result != e.mask {
+			t.Errorf("Expected %#016x, got %#016x", e.mask, result)
+		}
+	}
+}
+
+func TestWinLines(t *testing.T) {
+	expected := map[string]int{
+		"tictactoe":   8,
+		"connectfour": 69,
+		"gomoku":      96,
+	}
+	for game, count := range expected {
+		lines, err := WinLines(game)
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		if len(lines) != count {
+			t.Error("Expected", count, "lines for", game, ", got", len(lines))
+		}
+	}
+	lines, _ := WinLines("tictactoe")
+	found := map[uint64]bool{}
+	for _, line := range lines {
+		found[line] = true
+	}
+	// Top row and anti-diagonal.
+	for _, m := range []uint64{0x1c0, 0x054} {
+		if !found[m] {
+			t.Errorf("Expected line %#03x", m)
+		}
+	}
+	// Callers get their own copy.
+	lines[0] = 0
+	if lines, _ := WinLines("tictactoe"); lines[0] == 0 {
+		t.Error("Expected precomputed lines to be unchanged")
+	}
+	if _, err := WinLines("chess"); err == nil {
+		t.Error("Expected error for unknown game")
+	}
+}
+
+func TestSquareColors(t *testing.T) {
+	dark, light := DarkSquares(8, 8), LightSquares(8, 8)
+	if !IsBitSet(dark, 0) || IsBitSet(light, 0) {
+		t.Error("Expected a1 to be dark")
+	}
+	if !IsBitSet(light, 7) || IsBitSet(dark, 7) {
+		t.Error("Expected h1 to be light")
+	}
+	if dark != 0xaa55aa55aa55aa55 || dark|light != 0xffffffffffffffff {
+		t.Errorf("Expected %#016x, got %#016x", uint64(0xaa55aa55aa55aa55), dark)
+	}
+	// Odd board widths alternate along the bits.
+	if result := DarkSquares(3, 3); result != 0x155 {
+		t.Errorf("Expected %#03x, got %#03x", 0x155, result)
+	}
+	if result := LightSquares(3, 3); result != 0x0aa {
+		t.Errorf("Expected %#03x, got %#03x", 0x0aa, result)
+	}
+}
+
+func TestFills(t *testing.T) {
+	e4 := uint64(1) << 28
+	expected := []struct {
+		name     string
+		fill     func(uint64) uint64
+		i        uint64
+		expected uint64
+	}{
+		{"NorthFill", NorthFill, e4, 0x1010101010000000},
+		{"SouthFill", SouthFill, e4, 0x0000000010101010},
+		{"FileFill", FileFill, e4, 0x1010101010101010},
+		{"RankFill", RankFill, e4, 0x00000000ff000000},
+		{"RankFill", RankFill, 1, 0x00000000000000ff},
+		{"RankFill", RankFill, 1 << 63, 0xff00000000000000},
+		{"FileFill", FileFill, 0x0000000000000081, 0x8181818181818181},
+	}
+	for _, e := range expected {
+		if result := e.fill(e.i); result != e.expected {
+			t.Errorf("%s: Expected %#016x, got %#016x", e.name, e.expected, result)
+		}
+	}
+}
+
+func TestDilate(t *testing.T) {
+	var tests = []struct {
+		mask         uint64
+		steps        int
+		files, ranks int
+		expected     uint64
+	}{
+		// A single square grows into a 3x3 and then a 5x5 block.
+		{1 << 27, 1, 8, 8, 0x0000001c1c1c0000},
+		{1 << 27, 2, 8, 8, 0x00003e3e3e3e3e00},
+		{1 << 27, 0, 8, 8, 1 << 27},
+		// Squares on the edges do not wrap around or leave the board.
+		{1, 1, 8, 8, 0x0000000000000303},
+		{1 << 7, 1, 8, 8, 0x000000000000c0c0},
+		{1 << 4, 1, 3, 3, 0x1ff},
+		{1 << 7, 1, 3, 3, 0x1f8},
+		{1 << 8, 2, 3, 3, 0x1ff},
+	}
+	for _, test := range tests {
+		if result := Dilate(test.mask, test.steps, test.files, test.ranks); result != test.expected {
+			t.Errorf("Expected %#016x, got %#016x", test.expected, result)
+		}
+	}
+}
+
+func TestErode(t *testing.T) {
+	var tests = []struct {
+		mask         uint64
+		steps        int
+		files, ranks int
+		expected     uint64
+	}{
+		{0x0000001c1c1c0000, 1, 8, 8, 1 << 27},
+		{0x00003e3e3e3e3e00, 2, 8, 8, 1 << 27},
+		{0x00003e3e3e3e3e00, 3, 8, 8, 0},
+		// The edges of the board do not erode a full board.
+		{0xffffffffffffffff, 4, 8, 8, 0xffffffffffffffff},
+		{0x0000000000000303, 1, 8, 8, 1},
+		{0x1ff, 1, 3, 3, 0x1ff},
+		// Every edge of a smaller board behaves alike: two ranks or files
+		// against an edge of a 3x3 board erode to the one on the edge.
+		{0x1ff &^ (1 << 4), 1, 3, 3, 0},
+		{0x1f8, 1, 3, 3, 0x1c0},
+		{0x03f, 1, 3, 3, 0x007},
+		{0x1b6, 1, 3, 3, 0x124},
+		{0x0db, 1, 3, 3, 0x049},
+	}
+	for _, test := range tests {
+		if result := Erode(test.mask, test.steps, test.files, test.ranks); result != test.expected {
+			t.Errorf("Expected %#016x, got %#016x", test.expected, result)
+		}
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	// A connected L-shaped blob on an 8x8 board: a1, a2, a3, b3, c3.
+	blob := uint64(0x0000000000070101)
+	// A separate blob that touches the first only diagonally: d4.
+	other := uint64(0x0000000008000000)
+	mask := blob | other
+	if result := FloodFill(0x1, mask, 8); result != blob {
+		t.Errorf("Expected %#016x, got %#016x", blob, result)
+	}
+	if result := FloodFill(other, mask, 8); result != other {
+		t.Errorf("Expected %#016x, got %#016x", other, result)
+	}
+	if result := FloodFill(0x1|other, mask, 8); result != mask {
+		t.Errorf("Expected %#016x, got %#016x", mask, result)
+	}
+	// Seeds outside the mask reach nothing.
+	if result := FloodFill(0x2, mask, 8); result != 0 {
+		t.Errorf("Expected 0, got %#016x", result)
+	}
+	// The fill does not wrap from h1 to a2 on an 8x8 board...
+	if result := FloodFill(0x80, 0x180, 8); result != 0x80 {
+		t.Errorf("Expected %#x, got %#x", 0x80, result)
+	}
+	// ...nor from c1 to a2 on a 3x3 board.
+	if result := FloodFill(0x4, 0x1c, 3); result != 0x4 {
+		t.Errorf("Expected %#x, got %#x", 0x4, result)
+	}
+	// A board with no files has no squares to fill, and the fill ends.
+	if result := Dilate(0x1, 1, 0, 0); result != 0 {
+		t.Errorf("Expected 0, got %#x", result)
+	}
+	if result := FloodFill(0x1, 0x3, 0); result&^0x3 != 0 {
+		t.Errorf("Expected no more than %#x, got %#x", 0x3, result)
+	}
+}
+
+func TestFlipRotateLaws(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		x := r.Uint64()
+		involutions := map[string]func(uint64) uint64{
+			"FlipVertical":     FlipVertical,
+			"FlipHorizontal":   FlipHorizontal,
+			"FlipDiagonalA1H8": FlipDiagonalA1H8,
+			"FlipDiagonalA8H1": FlipDiagonalA8H1,
+			"Rotate180":        Rotate180,
+		}
+		for name, f := range involutions {
+			if result := f(f(x)); result != x {
+				t.Errorf("Expected %s twice to be identity for %#016x, got %#016x", name, x, result)
+			}
+		}
+		if result := Rotate90(Rotate90(Rotate90(Rotate90(x)))); result != x {
+			t.Errorf("Expected Rotate90 four times to be identity for %#016x, got %#016x", x, result)
+		}
+		if result := Rotate270(Rotate90(x)); result != x {
+			t.Errorf("Expected Rotate270 to undo Rotate90 for %#016x, got %#016x", x, result)
+		}
+		if Rotate90(Rotate90(x)) != Rotate180(x) {
+			t.Errorf("Expected Rotate90 twice to equal Rotate180 for %#016x", x)
+		}
+		if Rotate180(x) != FlipHorizontal(FlipVertical(x)) {
+			t.Errorf("Expected Rotate180 to equal FlipHorizontal after FlipVertical for %#016x", x)
+		}
+		if FlipDiagonalA8H1(x) != Rotate180(FlipDiagonalA1H8(x)) {
+			t.Errorf("Expected FlipDiagonalA8H1 to equal Rotate180 after FlipDiagonalA1H8 for %#016x", x)
+		}
+		if PopCount(Rotate90(x)) != PopCount(x) {
+			t.Errorf("Expected Rotate90 to preserve population count for %#016x", x)
+		}
+	}
+}
+
+func TestFlipRotateSquares(t *testing.T) {
+	// Each transform applied to a single square, given as (function, from, to)
+	// in algebraic notation.
+	tests := []struct {
+		name     string
+		f        func(uint64) uint64
+		from, to string
+	}{
+		{"FlipVertical", FlipVertical, "b1", "b8"},
+		{"FlipHorizontal", FlipHorizontal, "b1", "g1"},
+		{"FlipDiagonalA1H8", FlipDiagonalA1H8, "b1", "a2"},
+		{"FlipDiagonalA8H1", FlipDiagonalA8H1, "b1", "h7"},
+		{"Rotate90", Rotate90, "b1", "a7"},
+		{"Rotate180", Rotate180, "b1", "g8"},
+		{"Rotate270", Rotate270, "b1", "h2"},
+	}
+	for _, test := range tests {
+		var x uint64
+		SetBit(&x, AlgebraicToBit(test.from, 8))
+		expected := AlgebraicToBit(test.to, 8)
+		if result := LSB(test.f(x)); result != expected {
+			t.Error("Expected", test.name, "to move", test.from, "to", test.to, ", got", BitToAlgebraic(result, 8))
+		}
+	}
+}
+
+func TestPrintBitmap(t *testing.T) {
+	expected := "........\n" +
+		"........\n" +
+		"........\n" +
+		"........\n" +
+		"........\n" +
+		"........\n" +
+		"11111111\n" +
+		"1......1\n"
+	if result := PrintBitmap(0x000000000000ff81, 8, 8); result != expected {
+		t.Errorf("Expected\n%s, got\n%s", expected, result)
+	}
+	// A 3x3 diagonal.
+	expected = "..1\n.1.\n1..\n"
+	if result := PrintBitmap(0x111, 3, 3); result != expected {
+		t.Errorf("Expected\n%s, got\n%s", expected, result)
+	}
+}
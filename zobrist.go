@@ -0,0 +1,116 @@
+package bitboard
+
+import "math/rand"
+
+// zobristSeed seeds the Zobrist keys so that hashes are stable across runs.
+const zobristSeed = 0x5eed
+
+// Zobrist keys for each (bitmap, square) pair, the player to move, the
+// castling availability, and the en passant target square. Keys for bitmaps
+// and players beyond the first 64 are derived by zobristKey.
+var (
+	zobristPieces    [64][64]uint64
+	zobristSide      [64]uint64
+	zobristCastling  [16]uint64
+	zobristEnPassant [64]uint64
+)
+
+func init() {
+	r := rand.New(rand.NewSource(zobristSeed))
+	for i := range zobristPieces {
+		for p := range zobristPieces[i] {
+			zobristPieces[i][p] = r.Uint64()
+		}
+	}
+	for i := range zobristSide {
+		zobristSide[i] = r.Uint64()
+	}
+	for i := range zobristCastling {
+		zobristCastling[i] = r.Uint64()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = r.Uint64()
+	}
+}
+
+// ZobristHash returns a hash of the position suitable for transposition
+// tables. It combines a key for every piece on the board with keys for the
+// player to move, castling availability, and en passant target square.
+// Hashes are stable across runs for any number of bitmaps and players.
+func (b *Bitboard) ZobristHash() uint64 {
+	var h uint64
+	for i, m := range b.Bitmaps {
+		for ; m != 0; m &= m - 1 {
+			if i < len(zobristPieces) {
+				h ^= zobristPieces[i][LSB(m)]
+			} else {
+				h ^= zobristKey(uint64(i)<<6 | uint64(LSB(m)))
+			}
+		}
+	}
+	switch {
+	case b.SideToMove > 0 && b.SideToMove < len(zobristSide):
+		h ^= zobristSide[b.SideToMove]
+	case b.SideToMove != 0:
+		h ^= zobristKey(1<<63 | uint64(b.SideToMove))
+	}
+	if b.CastlingRights != 0 {
+		h ^= zobristCastling[b.CastlingRights&0x0f]
+	}
+	if b.EnPassant >= 0 && b.EnPassant < 64 {
+		h ^= zobristEnPassant[b.EnPassant]
+	}
+	return h
+}
+
+// zobristKey derives a key from n for bitmaps and players that do not fit in
+// the precomputed tables, using the SplitMix64 finaliser.
+func zobristKey(n uint64) uint64 {
+	z := (n ^ zobristSeed) + 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// HashWithState returns a hash identifying the position for repetition
+// detection. Positions repeat only if the same player is to move with the same
+// castling and en passant rights, so it extends ZobristHash, except that an en
+// passant target only counts when a chess pawn can actually capture onto it.
+func (b *Bitboard) HashWithState() uint64 {
+	h := b.ZobristHash()
+	if b.EnPassant >= 0 && b.EnPassant < 64 && !b.canCaptureEnPassant() {
+		h ^= zobristEnPassant[b.EnPassant]
+	}
+	return h
+}
+
+// canCaptureEnPassant reports whether a pawn of the player to move attacks the
+// en passant target square.
+func (b *Bitboard) canCaptureEnPassant() bool {
+	if len(b.Bitmaps) != 12 || b.SideToMove > Black {
+		return false
+	}
+	pawns := b.Bitmaps[b.SideToMove*6+Pawn]
+	return PawnAttacks(b.EnPassant, b.SideToMove^1)&pawns != 0
+}
+
+// A History records the hashes of positions reached during a game.
+type History struct {
+	Hashes []uint64
+}
+
+// Push records a position hash.
+func (h *History) Push(hash uint64) {
+	h.Hashes = append(h.Hashes, hash)
+}
+
+// CountRepetitions returns the number of times a position hash has occurred.
+func (h *History) CountRepetitions(hash uint64) int {
+	n := 0
+	for _, v := range h.Hashes {
+		if v == hash {
+			n++
+		}
+	}
+	return n
+}
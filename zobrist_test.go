@@ -0,0 +1,119 @@
+package bitboard
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestZobristHash(t *testing.T) {
+	a := NewChessBoard()
+	b := NewChessBoard()
+	if a.ZobristHash() != b.ZobristHash() {
+		t.Error("Expected identical positions to hash equal")
+	}
+	// Reaching the same position by transposition yields the same hash.
+	a, _ = NewChessBoardFromMoves([]string{"g1f3", "g8f6", "b1c3"})
+	b, _ = NewChessBoardFromMoves([]string{"b1c3", "g8f6", "g1f3"})
+	if a.ZobristHash() != b.ZobristHash() {
+		t.Error("Expected transposed positions to hash equal")
+	}
+	h := a.ZobristHash()
+	m := Move{From: 12, To: 20, Piece: WhitePawns}
+	u := a.MakeMove(m)
+	if a.ZobristHash() == h {
+		t.Error("Expected hash to change after a move")
+	}
+	a.UnmakeMove(m, u)
+	if a.ZobristHash() != h {
+		t.Error("Expected hash to be restored after UnmakeMove")
+	}
+	// Side to move, castling, and en passant state contribute to the hash.
+	a.SetToMove(a.ToMove() ^ 1)
+	if a.ZobristHash() == h {
+		t.Error("Expected hash to depend on side to move")
+	}
+	a.SetToMove(a.ToMove() ^ 1)
+	a.CastlingRights = WhiteKingside
+	if a.ZobristHash() == h {
+		t.Error("Expected hash to depend on castling availability")
+	}
+}
+
+func TestZobristHashStable(t *testing.T) {
+	// Keys are seeded, so the hash of the starting position never changes.
+	expected := uint64(0x8b3fbdbeed00b8f5)
+	b := NewChessBoard()
+	if result := b.ZobristHash(); result != expected {
+		t.Errorf("Expected %#016x, got %#016x", expected, result)
+	}
+}
+
+func TestZobristHashManyBitmaps(t *testing.T) {
+	symbols := make([]string, 70)
+	for i := range symbols {
+		symbols[i] = strconv.Itoa(i)
+	}
+	a, _ := NewMultiplayerBoard(8, 8, 70, symbols)
+	b, _ := NewMultiplayerBoard(8, 8, 70, symbols)
+	a.PlacePieceBit(69, 0)
+	b.PlacePieceBit(69, 0)
+	if a.ZobristHash() != b.ZobristHash() {
+		t.Error("Expected identical positions to hash equal")
+	}
+	h := a.ZobristHash()
+	if h == 0 {
+		t.Error("Expected a piece beyond the 64th bitmap to change the hash")
+	}
+	a.RemovePieceBit(69, 0)
+	a.PlacePieceBit(68, 0)
+	if a.ZobristHash() == h {
+		t.Error("Expected hash to depend on the bitmap")
+	}
+	// Players beyond the 64th also have keys.
+	h = a.ZobristHash()
+	a.SideToMove = 69
+	if a.ZobristHash() == h {
+		t.Error("Expected hash to depend on side to move")
+	}
+}
+
+func TestHashWithState(t *testing.T) {
+	// After 1. e4 the en passant target is set but no black pawn can capture.
+	a, _ := NewChessBoardFromMoves([]string{"e2e4"})
+	b, _ := ParseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq -")
+	if a.ZobristHash() == b.ZobristHash() {
+		t.Error("Expected ZobristHash to depend on the en passant target")
+	}
+	if a.HashWithState() != b.HashWithState() {
+		t.Error("Expected HashWithState to ignore an uncapturable en passant target")
+	}
+	// A capturable en passant target distinguishes positions.
+	a, _ = ParseFEN("4k3/8/8/8/3pP3/8/8/4K3 b - e3")
+	b, _ = ParseFEN("4k3/8/8/8/3pP3/8/8/4K3 b - -")
+	if a.HashWithState() == b.HashWithState() {
+		t.Error("Expected HashWithState to include a capturable en passant target")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	b := NewChessBoard()
+	var h History
+	h.Push(b.HashWithState())
+	// Both knights shuffle out and back twice, repeating the start position.
+	moves := []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1", "f6g8"}
+	for _, s := range moves {
+		from := b.AlgebraicToBit(s[0:2])
+		to := b.AlgebraicToBit(s[2:4])
+		b.MakeMove(Move{From: from, To: to, Piece: b.GetBitmapIndex(from)})
+		h.Push(b.HashWithState())
+	}
+	if result := h.CountRepetitions(h.Hashes[1]); result != 2 {
+		t.Error("Expected 2, got", result)
+	}
+	if result := h.CountRepetitions(NewChessBoard().HashWithState()); result != 3 {
+		t.Error("Expected threefold repetition, got", result)
+	}
+	if result := h.CountRepetitions(0); result != 0 {
+		t.Error("Expected 0, got", result)
+	}
+}